@@ -0,0 +1,308 @@
+package inpu
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultInitialLimit      = 20
+	defaultMaxLimit          = 200
+	defaultAIMDBackoff       = 0.9
+	defaultGradientSmoothing = 0.2
+	defaultGradientTolerance = 1.0
+	minimumGradient          = 0.5
+)
+
+// LimitSample is the result of a request that a LimitAlgorithm adjusts the limit with.
+type LimitSample struct {
+	// Latency is the time until the response headers are received.
+	Latency time.Duration
+	// InFlight is the number of requests in flight when the request finished, including itself.
+	InFlight int
+	// Overloaded reports whether the server signaled an overload, 429, 503 or a timeout by default.
+	Overloaded bool
+}
+
+// LimitAlgorithm adjusts the concurrency limit of a key after every request.
+type LimitAlgorithm interface {
+	// Update returns the new limit, it is kept between MinLimit and MaxLimit.
+	Update(limit int, sample LimitSample) int
+}
+
+// LimitChange is passed to AdaptiveConcurrencyConfig.OnLimitChange when the limit of a key changes.
+type LimitChange struct {
+	Key  string
+	From int
+	To   int
+}
+
+type AdaptiveConcurrencyConfig struct {
+	// Algorithm creates the algorithm of a key, every key has its own. Default is AIMDLimit(AIMDConfig{}).
+	Algorithm func() LimitAlgorithm
+	// InitialLimit is the limit of a key before any request finishes. Default is 20.
+	InitialLimit int
+	// MinLimit is the lowest limit. Default is 1.
+	MinLimit int
+	// MaxLimit is the highest limit. Default is 200.
+	MaxLimit int
+	// MaxQueue, QueueTimeout and FailFast control the queue like in ConcurrencyLimitConfig.
+	MaxQueue     int
+	QueueTimeout time.Duration
+	FailFast     bool
+	// KeyFunc returns the key a request is limited by. Default is the host of the request.
+	KeyFunc func(req *http.Request) string
+	// IsOverload reports whether the result of a request is an overload signal. By default, the statuses
+	// RetryMiddleware waits Retry-After for (429 and 503) and timeouts are overload signals.
+	IsOverload func(resp *http.Response, err error) bool
+	// OnLimitChange is called after the limit of a key changes.
+	OnLimitChange func(change LimitChange)
+}
+
+type adaptiveConcurrencyMiddleware struct {
+	config   AdaptiveConcurrencyConfig
+	mu       sync.Mutex
+	limiters map[string]*adaptiveLimiter
+}
+
+type adaptiveConcurrencyTransport struct {
+	*adaptiveConcurrencyMiddleware
+	next http.RoundTripper
+}
+
+type adaptiveLimiter struct {
+	*concurrencyLimiter
+	// algorithm is only called by update, under the lock of the limiter
+	algorithm LimitAlgorithm
+}
+
+// AdaptiveConcurrencyLimitMiddleware creates a bulkhead middleware like ConcurrencyLimitMiddleware whose limit per
+// host is adjusted after every request from the latency and the overload signals, so it grows while the backend is
+// healthy and shrinks during brownouts. AIMDLimit and GradientLimit are the built-in algorithms.
+// Usage:
+//
+//	client := New().Use(AdaptiveConcurrencyLimitMiddleware(AdaptiveConcurrencyConfig{
+//		Algorithm: GradientLimit(GradientConfig{}),
+//		MaxLimit:  100,
+//	}))
+func AdaptiveConcurrencyLimitMiddleware(config AdaptiveConcurrencyConfig) Middleware {
+	if config.Algorithm == nil {
+		config.Algorithm = AIMDLimit(AIMDConfig{})
+	}
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = defaultInitialLimit
+	}
+	if config.MinLimit <= 0 {
+		config.MinLimit = 1
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = max(defaultMaxLimit, config.InitialLimit)
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = KeyByHost
+	}
+	if config.IsOverload == nil {
+		config.IsOverload = isOverload
+	}
+
+	return &adaptiveConcurrencyMiddleware{
+		config:   config,
+		limiters: make(map[string]*adaptiveLimiter),
+	}
+}
+
+func (t *adaptiveConcurrencyMiddleware) ID() string {
+	return "adaptive-concurrency-limit-middleware"
+}
+
+func (t *adaptiveConcurrencyMiddleware) Priority() int {
+	return 21
+}
+
+func (t *adaptiveConcurrencyMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
+	return &adaptiveConcurrencyTransport{adaptiveConcurrencyMiddleware: t, next: next}
+}
+
+func (t *adaptiveConcurrencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := t.config.KeyFunc(req)
+	limiter := t.limiter(key)
+
+	start := time.Now()
+	queueDepth, err := limiter.acquire(req, ConcurrencyLimitConfig{
+		MaxQueue:     t.config.MaxQueue,
+		QueueTimeout: t.config.QueueTimeout,
+		FailFast:     t.config.FailFast,
+	})
+	hooksFromContext(req.Context()).bulkhead(BulkheadEvent{
+		Request:    req,
+		Key:        key,
+		QueueDepth: queueDepth,
+		Wait:       time.Since(start),
+		Err:        err,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sent := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	// a cancelled request does not tell anything about the server
+	if !errors.Is(err, context.Canceled) {
+		t.adjust(key, limiter, time.Since(sent), t.config.IsOverload(resp, err))
+	}
+
+	if err != nil || resp.Body == nil {
+		limiter.release()
+
+		return resp, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: limiter.release}
+
+	return resp, nil
+}
+
+func (t *adaptiveConcurrencyMiddleware) adjust(key string, limiter *adaptiveLimiter, latency time.Duration,
+	overloaded bool,
+) {
+	from, to := limiter.update(func(limit, inFlight int) int {
+		newLimit := limiter.algorithm.Update(limit, LimitSample{
+			Latency:    latency,
+			InFlight:   inFlight,
+			Overloaded: overloaded,
+		})
+
+		return min(max(newLimit, t.config.MinLimit), t.config.MaxLimit)
+	})
+
+	if from != to && t.config.OnLimitChange != nil {
+		t.config.OnLimitChange(LimitChange{Key: key, From: from, To: to})
+	}
+}
+
+func (t *adaptiveConcurrencyMiddleware) limiter(key string) *adaptiveLimiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limiter, ok := t.limiters[key]
+	if !ok {
+		limiter = &adaptiveLimiter{
+			concurrencyLimiter: newConcurrencyLimiter(t.config.InitialLimit),
+			algorithm:          t.config.Algorithm(),
+		}
+		t.limiters[key] = limiter
+	}
+
+	return limiter
+}
+
+func isOverload(resp *http.Response, err error) bool {
+	if err != nil {
+		var netError net.Error
+		if errors.As(err, &netError) && netError.Timeout() {
+			return true
+		}
+
+		return errors.Is(err, context.DeadlineExceeded)
+	}
+
+	return checkOverloadBasedOnStatusCode(resp)
+}
+
+type AIMDConfig struct {
+	// Increase is added to the limit after a successful request while at least half of the limit is in use.
+	// Default is 1.
+	Increase int
+	// Backoff multiplies the limit after an overload signal, between 0 and 1. Default is 0.9.
+	Backoff float64
+	// LatencyThreshold makes a request slower than it an overload signal. Zero disables it.
+	LatencyThreshold time.Duration
+}
+
+type aimdLimit struct {
+	config AIMDConfig
+}
+
+// AIMDLimit creates the additive increase, multiplicative decrease algorithm: the limit grows by Increase after
+// every successful request and is multiplied by Backoff after an overload signal.
+func AIMDLimit(config AIMDConfig) func() LimitAlgorithm {
+	if config.Increase <= 0 {
+		config.Increase = 1
+	}
+	if config.Backoff <= 0 || config.Backoff >= 1 {
+		config.Backoff = defaultAIMDBackoff
+	}
+
+	return func() LimitAlgorithm {
+		return &aimdLimit{config: config}
+	}
+}
+
+func (a *aimdLimit) Update(limit int, sample LimitSample) int {
+	if sample.Overloaded || (a.config.LatencyThreshold > 0 && sample.Latency > a.config.LatencyThreshold) {
+		return int(float64(limit) * a.config.Backoff)
+	}
+
+	// the limit is not raised while it is not the bottleneck
+	if sample.InFlight*2 >= limit {
+		return limit + a.config.Increase
+	}
+
+	return limit
+}
+
+type GradientConfig struct {
+	// Smoothing is the weight of the new limit against the current one, between 0 and 1. Default is 0.2.
+	Smoothing float64
+	// Tolerance is how much the latency can grow over the minimum before the limit is reduced. Default is 1,
+	// 2 means the limit is only reduced when the latency is more than twice the minimum.
+	Tolerance float64
+}
+
+type gradientLimit struct {
+	config     GradientConfig
+	minLatency time.Duration
+}
+
+// GradientLimit creates the gradient algorithm of Netflix concurrency-limits: the limit follows the ratio of the
+// minimum latency to the current latency, plus a queue of the square root of the limit to probe for more capacity.
+// An overload signal halves the new limit.
+func GradientLimit(config GradientConfig) func() LimitAlgorithm {
+	if config.Smoothing <= 0 || config.Smoothing > 1 {
+		config.Smoothing = defaultGradientSmoothing
+	}
+	if config.Tolerance < 1 {
+		config.Tolerance = defaultGradientTolerance
+	}
+
+	return func() LimitAlgorithm {
+		return &gradientLimit{config: config}
+	}
+}
+
+func (g *gradientLimit) Update(limit int, sample LimitSample) int {
+	if sample.Latency > 0 && (g.minLatency == 0 || sample.Latency < g.minLatency) {
+		g.minLatency = sample.Latency
+	}
+
+	gradient := minimumGradient
+	if !sample.Overloaded && sample.Latency > 0 {
+		gradient = g.config.Tolerance * float64(g.minLatency) / float64(sample.Latency)
+		gradient = min(max(gradient, minimumGradient), 1)
+	}
+
+	current := float64(limit)
+	newLimit := current*gradient + math.Sqrt(current)
+
+	// the limit is not raised while it is not the bottleneck
+	if newLimit > current && sample.InFlight*2 < limit {
+		return limit
+	}
+
+	return int(math.Round(current*(1-g.config.Smoothing) + newLimit*g.config.Smoothing))
+}
@@ -0,0 +1,82 @@
+package inpu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func (c *ClientSuite) Test_AIMDLimit() {
+	c.T().Parallel()
+	algorithm := AIMDLimit(AIMDConfig{LatencyThreshold: time.Second})()
+
+	c.Require().Equal(11, algorithm.Update(10, LimitSample{InFlight: 5}))
+	c.Require().Equal(10, algorithm.Update(10, LimitSample{InFlight: 4}))
+	c.Require().Equal(9, algorithm.Update(10, LimitSample{InFlight: 10, Overloaded: true}))
+	c.Require().Equal(9, algorithm.Update(10, LimitSample{InFlight: 10, Latency: 2 * time.Second}))
+}
+
+func (c *ClientSuite) Test_GradientLimit() {
+	c.T().Parallel()
+	algorithm := GradientLimit(GradientConfig{})()
+
+	// the queue of sqrt(limit) probes for more capacity while the latency is at the minimum
+	c.Require().Equal(21, algorithm.Update(20, LimitSample{InFlight: 20, Latency: 10 * time.Millisecond}))
+	c.Require().Equal(20, algorithm.Update(20, LimitSample{InFlight: 5, Latency: 10 * time.Millisecond}))
+	// doubled latency halves the new limit
+	c.Require().Equal(19, algorithm.Update(20, LimitSample{InFlight: 20, Latency: 20 * time.Millisecond}))
+	c.Require().Equal(19, algorithm.Update(20, LimitSample{InFlight: 20, Overloaded: true}))
+
+	tolerant := GradientLimit(GradientConfig{Tolerance: 2})()
+	tolerant.Update(20, LimitSample{InFlight: 20, Latency: 10 * time.Millisecond})
+	c.Require().Equal(21, tolerant.Update(20, LimitSample{InFlight: 20, Latency: 20 * time.Millisecond}))
+}
+
+func (c *ClientSuite) Test_AdaptiveConcurrencyLimit_Shrinks_On_Overload() {
+	c.T().Parallel()
+	var overloaded atomic.Bool
+	overloaded.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if overloaded.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	changes := make([]LimitChange, 0)
+	client := New().
+		BasePath(server.URL).
+		Use(AdaptiveConcurrencyLimitMiddleware(AdaptiveConcurrencyConfig{
+			InitialLimit: 4,
+			MinLimit:     2,
+			MaxLimit:     5,
+			Algorithm:    AIMDLimit(AIMDConfig{Backoff: 0.5}),
+			OnLimitChange: func(change LimitChange) {
+				mu.Lock()
+				defer mu.Unlock()
+				changes = append(changes, change)
+			},
+		}))
+
+	for range 3 {
+		c.Require().NoError(client.Get("/").Send())
+	}
+
+	key := server.Listener.Addr().String()
+	c.Require().Equal([]LimitChange{{Key: key, From: 4, To: 2}}, changes)
+
+	overloaded.Store(false)
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			c.Require().NoError(client.Get("/").Send())
+		})
+	}
+	wg.Wait()
+
+	c.Require().Equal(LimitChange{Key: key, From: 2, To: 3}, changes[1])
+	c.Require().LessOrEqual(changes[len(changes)-1].To, 5)
+}
@@ -0,0 +1,104 @@
+package inpu
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff decides how long RetryMiddleware waits before a retry. Implementations must be safe for concurrent use.
+type Backoff interface {
+	// Next returns the wait before the retry attempt, 1 for the first retry. previous is the wait before the
+	// previous retry, 0 for the first one.
+	Next(attempt int, previous time.Duration) time.Duration
+}
+
+// BackoffFunc is an adapter to use a function as a Backoff.
+type BackoffFunc func(attempt int, previous time.Duration) time.Duration
+
+func (f BackoffFunc) Next(attempt int, previous time.Duration) time.Duration {
+	return f(attempt, previous)
+}
+
+// ConstantBackoff waits the same time before every retry.
+func ConstantBackoff(wait time.Duration) Backoff {
+	return BackoffFunc(func(int, time.Duration) time.Duration {
+		return wait
+	})
+}
+
+// ExponentialBackoff waits initial before the first retry and multiplies the wait by multiplier before every other
+// retry, up to maxWait. Zero maxWait means the wait is not capped. All the clients retry at the same time after an
+// outage, a jittered backoff spreads the retries.
+func ExponentialBackoff(initial, maxWait time.Duration, multiplier float64) Backoff {
+	return BackoffFunc(func(attempt int, _ time.Duration) time.Duration {
+		return capBackoff(exponential(initial, multiplier, attempt), maxWait)
+	})
+}
+
+// FullJitterBackoff waits a random time between 0 and the exponential backoff of base, doubling before every retry
+// up to maxWait. It spreads the retries the most.
+func FullJitterBackoff(base, maxWait time.Duration) Backoff {
+	return BackoffFunc(func(attempt int, _ time.Duration) time.Duration {
+		return randomDuration(0, capBackoff(exponential(base, 2, attempt), maxWait))
+	})
+}
+
+// EqualJitterBackoff waits half of the exponential backoff of base plus a random time up to the other half,
+// so a retry always waits at least half of the backoff.
+func EqualJitterBackoff(base, maxWait time.Duration) Backoff {
+	return BackoffFunc(func(attempt int, _ time.Duration) time.Duration {
+		wait := capBackoff(exponential(base, 2, attempt), maxWait)
+
+		return wait/2 + randomDuration(0, wait/2)
+	})
+}
+
+// DecorrelatedJitterBackoff waits a random time between base and three times the previous wait, up to maxWait.
+func DecorrelatedJitterBackoff(base, maxWait time.Duration) Backoff {
+	return BackoffFunc(func(_ int, previous time.Duration) time.Duration {
+		return capBackoff(randomDuration(base, max(base, previous*3)), maxWait)
+	})
+}
+
+// FibonacciBackoff waits base times the Fibonacci number of the attempt (1, 1, 2, 3, 5, 8...), up to maxWait.
+// It grows slower than the exponential backoff.
+func FibonacciBackoff(base, maxWait time.Duration) Backoff {
+	return BackoffFunc(func(attempt int, _ time.Duration) time.Duration {
+		previous, current := 0, 1
+		for range attempt - 1 {
+			previous, current = current, previous+current
+			if maxWait > 0 && base*time.Duration(current) > maxWait {
+				return maxWait
+			}
+		}
+
+		return capBackoff(base*time.Duration(current), maxWait)
+	})
+}
+
+func exponential(base time.Duration, multiplier float64, attempt int) time.Duration {
+	wait := float64(base) * math.Pow(multiplier, float64(attempt-1))
+	if wait >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return time.Duration(wait)
+}
+
+func capBackoff(wait, maxWait time.Duration) time.Duration {
+	if maxWait > 0 && wait > maxWait {
+		return maxWait
+	}
+
+	return wait
+}
+
+// randomDuration returns a random duration in [from, to), from if the range is empty.
+func randomDuration(from, to time.Duration) time.Duration {
+	if to <= from {
+		return from
+	}
+
+	return from + rand.N(to-from)
+}
@@ -0,0 +1,55 @@
+package inpu
+
+import (
+	"time"
+)
+
+func (c *ClientSuite) Test_Backoff_Deterministic() {
+	c.T().Parallel()
+
+	exponential := ExponentialBackoff(100*time.Millisecond, time.Second, 2)
+	fibonacci := FibonacciBackoff(100*time.Millisecond, time.Second)
+	constant := ConstantBackoff(300 * time.Millisecond)
+
+	expectedExponential := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	expectedFibonacci := []time.Duration{100, 100, 200, 300, 500, 800, 1000}
+	for i, expected := range expectedExponential {
+		c.Require().Equal(expected*time.Millisecond, exponential.Next(i+1, 0))
+	}
+	for i, expected := range expectedFibonacci {
+		c.Require().Equal(expected*time.Millisecond, fibonacci.Next(i+1, 0))
+	}
+	c.Require().Equal(300*time.Millisecond, constant.Next(5, time.Second))
+}
+
+func (c *ClientSuite) Test_Backoff_Jitter_Stays_In_Range() {
+	c.T().Parallel()
+	base := 100 * time.Millisecond
+	maxWait := time.Second
+
+	fullJitter := FullJitterBackoff(base, maxWait)
+	equalJitter := EqualJitterBackoff(base, maxWait)
+	decorrelatedJitter := DecorrelatedJitterBackoff(base, maxWait)
+
+	for range 100 {
+		for attempt := 1; attempt <= 6; attempt++ {
+			ceiling := min(base<<(attempt-1), maxWait)
+
+			wait := fullJitter.Next(attempt, 0)
+			c.Require().GreaterOrEqual(wait, time.Duration(0))
+			c.Require().Less(wait, ceiling)
+
+			wait = equalJitter.Next(attempt, 0)
+			c.Require().GreaterOrEqual(wait, ceiling/2)
+			c.Require().Less(wait, ceiling)
+		}
+
+		previous := time.Duration(0)
+		for attempt := 1; attempt <= 6; attempt++ {
+			wait := decorrelatedJitter.Next(attempt, previous)
+			c.Require().GreaterOrEqual(wait, base)
+			c.Require().LessOrEqual(wait, min(max(base, previous*3), maxWait))
+			previous = wait
+		}
+	}
+}
@@ -0,0 +1,531 @@
+package inpu
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheableByDefault are the statuses that can be cached without explicit freshness, RFC 9110 section 15.1.
+var cacheableByDefault = []int{
+	http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusPartialContent,
+	http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusPermanentRedirect,
+	http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusGone, http.StatusRequestURITooLong,
+	http.StatusNotImplemented,
+}
+
+// cacheCredentialHeaders are part of the cache key, so a client shared by many users never serves the cached response
+// of a user to another one.
+var cacheCredentialHeaders = []string{HeaderAuthorization, HeaderCookie}
+
+// CacheStatus tells how a request was answered by CacheMiddleware.
+type CacheStatus int
+
+const (
+	// CacheMiss means the response was fetched from the server.
+	CacheMiss CacheStatus = iota
+	// CacheHit means a fresh response was served from the cache.
+	CacheHit
+	// CacheRevalidated means the cached response was served after the server answered 304 Not Modified.
+	CacheRevalidated
+	// CacheStale means a stale response was served, because of stale-while-revalidate or stale-if-error.
+	CacheStale
+)
+
+func (s CacheStatus) String() string {
+	switch s {
+	case CacheMiss:
+		return "miss"
+	case CacheHit:
+		return "hit"
+	case CacheRevalidated:
+		return "revalidated"
+	case CacheStale:
+		return "stale"
+	default:
+		return fmt.Sprintf("CacheStatus(%d)", int(s))
+	}
+}
+
+// CacheOption configures the cache middleware.
+type CacheOption func(*cacheMiddleware)
+
+// WithSharedCache makes the cache behave like a shared cache: responses with private are not stored, s-maxage is
+// used and the responses of requests with an Authorization header are only stored when they allow it.
+// By default, the cache is private to the client.
+func WithSharedCache() CacheOption {
+	return func(m *cacheMiddleware) {
+		m.shared = true
+	}
+}
+
+type cacheMiddleware struct {
+	store        CacheStore
+	shared       bool
+	mu           sync.Mutex
+	revalidating map[string]bool
+}
+
+type cacheTransport struct {
+	*cacheMiddleware
+	next http.RoundTripper
+}
+
+// cacheEntry is a stored response with the times it was requested and received, to calculate its age.
+type cacheEntry struct {
+	RequestTime  time.Time         `json:"request_time"`
+	ResponseTime time.Time         `json:"response_time"`
+	Vary         map[string]string `json:"vary,omitempty"`
+	Response     []byte            `json:"response"`
+}
+
+// CacheMiddleware creates an HTTP cache (RFC 9111) for the GET requests. It honors the Cache-Control directives
+// max-age, s-maxage, no-store, no-cache, must-revalidate, private, public, stale-while-revalidate and
+// stale-if-error, the Expires and Vary headers, and revalidates stale responses with If-None-Match and
+// If-Modified-Since. A successful unsafe request (POST, PUT, PATCH, DELETE) removes the cached response of its URL.
+// The responses are cached by URL and by a hash of the Authorization and Cookie headers of the request, so the
+// requests with other credentials do not get them.
+// Hooks.OnCacheHit and Hooks.OnCacheMiss report how every request was answered.
+// Usage:
+//
+//	client := New().Use(CacheMiddleware(NewMemoryCacheStore(1000)))
+func CacheMiddleware(store CacheStore, opts ...CacheOption) Middleware {
+	m := &cacheMiddleware{
+		store:        store,
+		revalidating: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+func (t *cacheMiddleware) ID() string {
+	return "cache-middleware"
+}
+
+func (t *cacheMiddleware) Priority() int {
+	return 30
+}
+
+func (t *cacheMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
+	return &cacheTransport{cacheMiddleware: t, next: next}
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := cacheKey(req)
+	if req.Method != http.MethodGet {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && !isSafeMethod(req.Method) && resp.StatusCode < http.StatusBadRequest {
+			t.delete(req.Context(), key)
+			if url := req.URL.String(); url != key {
+				t.delete(req.Context(), url)
+			}
+		}
+
+		return resp, err
+	}
+
+	requestDirectives := parseCacheControl(req.Header)
+	if requestDirectives.has("no-store") {
+		return t.fetch(req, key)
+	}
+
+	entry := t.load(req.Context(), key)
+	if entry == nil || !entry.matches(req) {
+		return t.fetch(req, key)
+	}
+
+	cached, err := entry.response(req)
+	if err != nil {
+		return t.fetch(req, key)
+	}
+
+	now := ExtractClockFromContext(req.Context()).Now()
+	directives := parseCacheControl(cached.Header)
+	age := entry.age(cached, now)
+	lifetime := t.freshnessLifetime(cached, directives, now)
+	cached.Header.Set(HeaderAge, strconv.Itoa(int(age.Seconds())))
+
+	if t.isFresh(age, lifetime, directives, requestDirectives) {
+		t.hit(req, key, CacheHit, age)
+
+		return cached, nil
+	}
+
+	canServeStale := !directives.has("must-revalidate") && !requestDirectives.has("no-cache")
+	if window, ok := directives.seconds("stale-while-revalidate"); ok && canServeStale && age < lifetime+window {
+		t.revalidateInBackground(req, key, entry)
+		t.hit(req, key, CacheStale, age)
+
+		return cached, nil
+	}
+
+	resp, err := t.revalidate(req, key, cached)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		window, ok := directives.seconds("stale-if-error")
+		if requestWindow, requestOk := requestDirectives.seconds("stale-if-error"); requestOk {
+			window, ok = requestWindow, true
+		}
+
+		if ok && canServeStale && age < lifetime+window {
+			DrainBodyAndClose(resp)
+			t.hit(req, key, CacheStale, age)
+
+			return cached, nil
+		}
+	}
+	if resp == cached {
+		t.hit(req, key, CacheRevalidated, 0)
+
+		return cached, nil
+	}
+
+	DrainBodyAndClose(cached)
+	if err == nil {
+		t.miss(req, key)
+	}
+
+	return resp, err
+}
+
+func (t *cacheMiddleware) isFresh(age, lifetime time.Duration, directives, requestDirectives cacheControl) bool {
+	if directives.has("no-cache") || requestDirectives.has("no-cache") {
+		return false
+	}
+
+	if maxAge, ok := requestDirectives.seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+
+	return age < lifetime
+}
+
+// fetch sends the request and stores the response if it is storable.
+func (t *cacheTransport) fetch(req *http.Request, key string) (*http.Response, error) {
+	requestTime := ExtractClockFromContext(req.Context()).Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	t.miss(req, key)
+
+	if t.isStorable(req, resp) {
+		t.save(req, key, resp, requestTime)
+	}
+
+	return resp, nil
+}
+
+// revalidate sends the request with the validators of the cached response. If the server answers
+// 304 Not Modified, the cached response is updated with the new headers and returned.
+func (t *cacheTransport) revalidate(req *http.Request, key string, cached *http.Response) (*http.Response, error) {
+	conditional := req.Clone(req.Context())
+	if eTag := cached.Header.Get(HeaderETag); eTag != "" {
+		conditional.Header.Set(HeaderIfNoneMatch, eTag)
+	}
+	if lastModified := cached.Header.Get(HeaderLastModified); lastModified != "" {
+		conditional.Header.Set(HeaderIfModifiedSince, lastModified)
+	}
+
+	requestTime := ExtractClockFromContext(req.Context()).Now()
+	resp, err := t.next.RoundTrip(conditional)
+	if err != nil || resp.StatusCode != http.StatusNotModified {
+		if err == nil && t.isStorable(req, resp) {
+			t.save(req, key, resp, requestTime)
+		} else if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			t.delete(req.Context(), key)
+		}
+
+		return resp, err
+	}
+	DrainBodyAndClose(resp)
+
+	for name, values := range resp.Header {
+		if name != HeaderContentLength {
+			cached.Header[name] = values
+		}
+	}
+	cached.Header.Del(HeaderAge)
+
+	// the cached response is stored again with the new headers and times
+	t.save(req, key, cached, requestTime)
+	cached.Header.Set(HeaderAge, "0")
+
+	return cached, nil
+}
+
+func (t *cacheTransport) revalidateInBackground(req *http.Request, key string, entry *cacheEntry) {
+	t.mu.Lock()
+	if t.revalidating[key] {
+		t.mu.Unlock()
+
+		return
+	}
+	t.revalidating[key] = true
+	t.mu.Unlock()
+
+	background := req.Clone(context.WithoutCancel(req.Context()))
+	stale, err := entry.response(background)
+	if err != nil {
+		t.finishRevalidation(key)
+
+		return
+	}
+
+	go func() {
+		defer t.finishRevalidation(key)
+
+		resp, err := t.revalidate(background, key, stale)
+		if err == nil {
+			DrainBodyAndClose(resp)
+		}
+	}()
+}
+
+func (t *cacheMiddleware) finishRevalidation(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.revalidating, key)
+}
+
+func (t *cacheMiddleware) hit(req *http.Request, key string, status CacheStatus, age time.Duration) {
+	hooksFromContext(req.Context()).cacheHit(CacheEvent{Request: req, Key: key, Status: status, Age: age})
+}
+
+func (t *cacheMiddleware) miss(req *http.Request, key string) {
+	hooksFromContext(req.Context()).cacheMiss(CacheEvent{Request: req, Key: key, Status: CacheMiss})
+}
+
+// cacheKey returns the URL of the request, followed by the hash of its credentials when it has any.
+func cacheKey(req *http.Request) string {
+	credentials := sha256.New()
+	credentialed := false
+	for _, name := range cacheCredentialHeaders {
+		for _, value := range req.Header.Values(name) {
+			credentialed = true
+			_, _ = fmt.Fprintf(credentials, "%s: %s\n", name, value)
+		}
+	}
+	if !credentialed {
+		return req.URL.String()
+	}
+
+	return req.URL.String() + " " + hex.EncodeToString(credentials.Sum(nil))
+}
+
+func (t *cacheMiddleware) isStorable(req *http.Request, resp *http.Response) bool {
+	directives := parseCacheControl(resp.Header)
+	if directives.has("no-store") || parseCacheControl(req.Header).has("no-store") {
+		return false
+	}
+
+	if slices.Contains(varyHeaders(resp), "*") {
+		return false
+	}
+
+	if t.shared {
+		if directives.has("private") {
+			return false
+		}
+
+		if req.Header.Get(HeaderAuthorization) != "" && !directives.has("public") &&
+			!directives.has("s-maxage") && !directives.has("must-revalidate") {
+			return false
+		}
+	}
+
+	return directives.has("public") || directives.has("private") || directives.has("max-age") ||
+		(t.shared && directives.has("s-maxage")) || resp.Header.Get(HeaderExpires) != "" ||
+		slices.Contains(cacheableByDefault, resp.StatusCode)
+}
+
+// freshnessLifetime returns how long the response is fresh after it was generated, RFC 9111 section 4.2.1.
+func (t *cacheMiddleware) freshnessLifetime(resp *http.Response, directives cacheControl, now time.Time,
+) time.Duration {
+	if t.shared {
+		if lifetime, ok := directives.seconds("s-maxage"); ok {
+			return lifetime
+		}
+	}
+
+	if lifetime, ok := directives.seconds("max-age"); ok {
+		return lifetime
+	}
+
+	date := responseDate(resp, now)
+	if expires := resp.Header.Get(HeaderExpires); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+
+		return expiresAt.Sub(date)
+	}
+
+	// heuristic freshness, 10% of the time since the last modification
+	if lastModified, err := http.ParseTime(resp.Header.Get(HeaderLastModified)); err == nil &&
+		slices.Contains(cacheableByDefault, resp.StatusCode) && date.After(lastModified) {
+		return date.Sub(lastModified) / 10
+	}
+
+	return 0
+}
+
+func (t *cacheMiddleware) save(req *http.Request, key string, resp *http.Response, requestTime time.Time) {
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return
+	}
+
+	vary := make(map[string]string)
+	for _, name := range varyHeaders(resp) {
+		vary[name] = strings.Join(req.Header.Values(name), ", ")
+	}
+
+	data, err := jsonMarshal(cacheEntry{
+		RequestTime:  requestTime,
+		ResponseTime: ExtractClockFromContext(req.Context()).Now(),
+		Vary:         vary,
+		Response:     dump,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := t.store.Set(req.Context(), key, data); err != nil {
+		ExtractLoggerFromContext(req.Context()).Warn(req.Context(), "[CACHE] Could not store %s: %v", key, err)
+	}
+}
+
+func (t *cacheMiddleware) load(ctx context.Context, key string) *cacheEntry {
+	data, ok, err := t.store.Get(ctx, key)
+	if err != nil {
+		ExtractLoggerFromContext(ctx).Warn(ctx, "[CACHE] Could not load %s: %v", key, err)
+
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if err := jsonUnmarshalFromReader(bytes.NewReader(data), entry); err != nil {
+		return nil
+	}
+
+	return entry
+}
+
+func (t *cacheMiddleware) delete(ctx context.Context, key string) {
+	if err := t.store.Delete(ctx, key); err != nil {
+		ExtractLoggerFromContext(ctx).Warn(ctx, "[CACHE] Could not delete %s: %v", key, err)
+	}
+}
+
+// matches reports whether the request has the same values as the stored one for the headers in Vary.
+func (e *cacheEntry) matches(req *http.Request) bool {
+	for name, value := range e.Vary {
+		if strings.Join(req.Header.Values(name), ", ") != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *cacheEntry) response(req *http.Request) (*http.Response, error) {
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(e.Response)), req)
+}
+
+// age returns the current age of the stored response, RFC 9111 section 4.2.3.
+func (e *cacheEntry) age(resp *http.Response, now time.Time) time.Duration {
+	apparentAge := max(0, e.ResponseTime.Sub(responseDate(resp, e.ResponseTime)))
+
+	ageValue := time.Duration(0)
+	if seconds, err := strconv.Atoi(resp.Header.Get(HeaderAge)); err == nil && seconds > 0 {
+		ageValue = time.Duration(seconds) * time.Second
+	}
+	correctedAge := ageValue + e.ResponseTime.Sub(e.RequestTime)
+
+	return max(apparentAge, correctedAge) + now.Sub(e.ResponseTime)
+}
+
+// responseDate returns the Date header of the response, fallback when it is missing or invalid.
+func responseDate(resp *http.Response, fallback time.Time) time.Time {
+	date, err := http.ParseTime(resp.Header.Get(HeaderDate))
+	if err != nil {
+		return fallback
+	}
+
+	return date
+}
+
+func varyHeaders(resp *http.Response) []string {
+	names := make([]string, 0)
+	for _, value := range resp.Header.Values(HeaderVary) {
+		for name := range strings.SplitSeq(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	return names
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead ||
+		method == http.MethodOptions || method == http.MethodTrace
+}
+
+// cacheControl are the directives of a Cache-Control header, the names are lower case.
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	directives := make(cacheControl)
+	for _, value := range header.Values(HeaderCacheControl) {
+		for directive := range strings.SplitSeq(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(argument, `"`)
+		}
+	}
+
+	return directives
+}
+
+func (c cacheControl) has(name string) bool {
+	_, ok := c[name]
+
+	return ok
+}
+
+// seconds returns the argument of the directive as a duration, invalid arguments are ignored.
+func (c cacheControl) seconds(name string) (time.Duration, bool) {
+	value, ok := c[name]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
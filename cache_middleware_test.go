@@ -0,0 +1,272 @@
+package inpu
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func newCacheServer(calls *atomic.Int32, handler func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := calls.Add(1)
+		w.Header().Set(HeaderDate, time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set(HeaderContentType, MimeTypeJson)
+		handler(w, r)
+		// the body is dropped by the server on 304 Not Modified
+		_, _ = fmt.Fprintf(w, `{"call":%d}`, call)
+	}))
+}
+
+func (c *ClientSuite) Test_Cache_Serves_Fresh_Responses() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderCacheControl, "max-age=60")
+	})
+	defer server.Close()
+
+	var mu sync.Mutex
+	statuses := make([]CacheStatus, 0)
+	client := New().
+		BasePath(server.URL).
+		Use(CacheMiddleware(NewMemoryCacheStore(10))).
+		Hooks(Hooks{
+			OnCacheHit: func(event CacheEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				statuses = append(statuses, event.Status)
+			},
+			OnCacheMiss: func(event CacheEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				statuses = append(statuses, event.Status)
+			},
+		})
+
+	for range 3 {
+		result := map[string]int{}
+		c.Require().NoError(client.Get("/").OnOk(ThenUnmarshalJsonTo(&result)).Send())
+		c.Require().Equal(1, result["call"])
+	}
+
+	c.Require().EqualValues(1, calls.Load())
+	c.Require().Equal([]CacheStatus{CacheMiss, CacheHit, CacheHit}, statuses)
+}
+
+func (c *ClientSuite) Test_Cache_No_Store() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/no-store" {
+			w.Header().Set(HeaderCacheControl, "no-store")
+		} else {
+			w.Header().Set(HeaderCacheControl, "max-age=60")
+		}
+	})
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(CacheMiddleware(NewMemoryCacheStore(10)))
+
+	c.Require().NoError(client.Get("/no-store").Send())
+	c.Require().NoError(client.Get("/no-store").Send())
+	c.Require().EqualValues(2, calls.Load())
+
+	// a request with no-store is not answered from the cache
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().NoError(client.Get("/").Header(HeaderCacheControl, "no-store").Send())
+	c.Require().EqualValues(4, calls.Load())
+}
+
+func (c *ClientSuite) Test_Cache_Revalidates_With_ETag() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderCacheControl, "no-cache")
+		w.Header().Set(HeaderETag, `"v1"`)
+		if r.Header.Get(HeaderIfNoneMatch) == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+		}
+	})
+	defer server.Close()
+
+	var revalidated atomic.Int32
+	client := New().
+		BasePath(server.URL).
+		Use(CacheMiddleware(NewMemoryCacheStore(10))).
+		Hooks(Hooks{OnCacheHit: func(event CacheEvent) {
+			if event.Status == CacheRevalidated {
+				revalidated.Add(1)
+			}
+		}})
+
+	for range 3 {
+		result := map[string]int{}
+		c.Require().NoError(client.Get("/").OnOk(ThenUnmarshalJsonTo(&result)).Send())
+		c.Require().Equal(1, result["call"])
+	}
+
+	c.Require().EqualValues(3, calls.Load())
+	c.Require().EqualValues(2, revalidated.Load())
+}
+
+func (c *ClientSuite) Test_Cache_Vary() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderCacheControl, "max-age=60")
+		w.Header().Set(HeaderVary, HeaderAcceptLanguage)
+	})
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(CacheMiddleware(NewMemoryCacheStore(10)))
+
+	c.Require().NoError(client.Get("/").Header(HeaderAcceptLanguage, "en").Send())
+	c.Require().NoError(client.Get("/").Header(HeaderAcceptLanguage, "en").Send())
+	c.Require().EqualValues(1, calls.Load())
+
+	c.Require().NoError(client.Get("/").Header(HeaderAcceptLanguage, "fr").Send())
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_Cache_Stale_While_Revalidate() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderCacheControl, "max-age=0, stale-while-revalidate=60")
+	})
+	defer server.Close()
+
+	var stale atomic.Int32
+	client := New().
+		BasePath(server.URL).
+		Use(CacheMiddleware(NewMemoryCacheStore(10))).
+		Hooks(Hooks{OnCacheHit: func(event CacheEvent) {
+			if event.Status == CacheStale {
+				stale.Add(1)
+			}
+		}})
+
+	c.Require().NoError(client.Get("/").Send())
+
+	result := map[string]int{}
+	c.Require().NoError(client.Get("/").OnOk(ThenUnmarshalJsonTo(&result)).Send())
+	c.Require().Equal(1, result["call"])
+	c.Require().EqualValues(1, stale.Load())
+
+	// the background revalidation stores the new response
+	c.Require().Eventually(func() bool {
+		return calls.Load() == 2
+	}, time.Second, 5*time.Millisecond)
+	c.Require().Eventually(func() bool {
+		result := map[string]int{}
+		c.Require().NoError(client.Get("/").OnOk(ThenUnmarshalJsonTo(&result)).Send())
+
+		return result["call"] >= 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func (c *ClientSuite) Test_Cache_Stale_If_Error() {
+	c.T().Parallel()
+	var failing atomic.Bool
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderCacheControl, "max-age=0, stale-if-error=60")
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(CacheMiddleware(NewMemoryCacheStore(10)))
+
+	c.Require().NoError(client.Get("/").Send())
+
+	failing.Store(true)
+	result := map[string]int{}
+	c.Require().NoError(client.Get("/").
+		OnOk(ThenUnmarshalJsonTo(&result)).
+		Send())
+	c.Require().Equal(1, result["call"])
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_Cache_Invalidated_By_Unsafe_Method() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderCacheControl, "max-age=60")
+	})
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(CacheMiddleware(NewMemoryCacheStore(10)))
+
+	c.Require().NoError(client.Get("/items").Send())
+	c.Require().NoError(client.Get("/items").Send())
+	c.Require().EqualValues(1, calls.Load())
+
+	c.Require().NoError(client.Post("/items", nil).Send())
+	c.Require().NoError(client.Get("/items").Send())
+	c.Require().EqualValues(3, calls.Load())
+}
+
+func (c *ClientSuite) Test_Cache_Keyed_By_Credentials() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderCacheControl, "max-age=60")
+	})
+	defer server.Close()
+
+	keys := make([]string, 0)
+	client := New().
+		BasePath(server.URL).
+		Use(CacheMiddleware(NewMemoryCacheStore(10))).
+		Hooks(Hooks{
+			OnCacheMiss: func(event CacheEvent) {
+				keys = append(keys, event.Key)
+			},
+		})
+	send := func(header, value string) int {
+		result := map[string]int{}
+		c.Require().NoError(client.Get("/me").Header(header, value).OnOk(ThenUnmarshalJsonTo(&result)).Send())
+
+		return result["call"]
+	}
+
+	c.Require().Equal(1, send(HeaderAuthorization, "Bearer alice"))
+	c.Require().Equal(2, send(HeaderAuthorization, "Bearer bob"))
+	c.Require().Equal(3, send(HeaderCookie, "session=alice"))
+	c.Require().Equal(1, send(HeaderAuthorization, "Bearer alice"))
+	c.Require().Equal(2, send(HeaderAuthorization, "Bearer bob"))
+	c.Require().Equal(3, send(HeaderCookie, "session=alice"))
+	c.Require().EqualValues(3, calls.Load())
+
+	// the credentials are hashed, they are not written to the store
+	c.Require().Len(keys, 3)
+	for _, key := range keys {
+		c.Require().NotContains(key, "alice")
+		c.Require().NotContains(key, "bob")
+	}
+}
+
+func (c *ClientSuite) Test_Cache_Shared_Does_Not_Store_Private() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderCacheControl, "private, max-age=60")
+	})
+	defer server.Close()
+
+	shared := New().BasePath(server.URL).Use(CacheMiddleware(NewMemoryCacheStore(10), WithSharedCache()))
+	c.Require().NoError(shared.Get("/").Send())
+	c.Require().NoError(shared.Get("/").Send())
+	c.Require().EqualValues(2, calls.Load())
+
+	private := New().BasePath(server.URL).Use(CacheMiddleware(NewMemoryCacheStore(10)))
+	c.Require().NoError(private.Get("/").Send())
+	c.Require().NoError(private.Get("/").Send())
+	c.Require().EqualValues(3, calls.Load())
+}
@@ -0,0 +1,157 @@
+package inpu
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const defaultMaxCacheEntries = 1000
+
+// CacheStore stores the responses of CacheMiddleware. Implementations must be safe for concurrent use.
+// A store error is logged and handled as a cache miss, so a store backed by a network service like Redis
+// does not make the requests fail.
+type CacheStore interface {
+	// Get returns the value of the key, and false if there is no value.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+type memoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryCacheEntry struct {
+	key   string
+	value []byte
+}
+
+// NewMemoryCacheStore creates an in-memory store that evicts the least recently used entry when it has maxEntries.
+// Default maxEntries is 1000.
+func NewMemoryCacheStore(maxEntries int) CacheStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxCacheEntries
+	}
+
+	return &memoryCacheStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (s *memoryCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	s.lru.MoveToFront(element)
+
+	return element.Value.(*memoryCacheEntry).value, true, nil
+}
+
+func (s *memoryCacheStore) Set(_ context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*memoryCacheEntry).value = value
+		s.lru.MoveToFront(element)
+
+		return nil
+	}
+
+	s.entries[key] = s.lru.PushFront(&memoryCacheEntry{key: key, value: value})
+	if s.lru.Len() > s.maxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+
+	return nil
+}
+
+func (s *memoryCacheStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.lru.Remove(element)
+		delete(s.entries, key)
+	}
+
+	return nil
+}
+
+type diskCacheStore struct {
+	dir string
+}
+
+// NewDiskCacheStore creates a store that keeps every entry in a file in dir. The directory is created if it does
+// not exist. The entries are not evicted, the files can be removed at any time.
+func NewDiskCacheStore(dir string) CacheStore {
+	return &diskCacheStore{dir: dir}
+}
+
+func (s *diskCacheStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (s *diskCacheStore) Set(_ context.Context, key string, value []byte) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	// the entry is written to a temporary file first, so a concurrent Get never reads a partial entry
+	file, err := os.CreateTemp(s.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(value); err != nil {
+		file.Close()
+
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path(key))
+}
+
+func (s *diskCacheStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *diskCacheStore) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return filepath.Join(s.dir, hex.EncodeToString(hash[:]))
+}
@@ -0,0 +1,57 @@
+package inpu
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+func (c *ClientSuite) Test_MemoryCacheStore_Evicts_Least_Recently_Used() {
+	c.T().Parallel()
+	ctx := context.Background()
+	store := NewMemoryCacheStore(2)
+
+	c.Require().NoError(store.Set(ctx, "a", []byte("1")))
+	c.Require().NoError(store.Set(ctx, "b", []byte("2")))
+	_, ok, err := store.Get(ctx, "a")
+	c.Require().NoError(err)
+	c.Require().True(ok)
+
+	c.Require().NoError(store.Set(ctx, "c", []byte("3")))
+	_, ok, _ = store.Get(ctx, "b")
+	c.Require().False(ok)
+
+	value, ok, _ := store.Get(ctx, "a")
+	c.Require().True(ok)
+	c.Require().Equal([]byte("1"), value)
+
+	c.Require().NoError(store.Delete(ctx, "a"))
+	_, ok, _ = store.Get(ctx, "a")
+	c.Require().False(ok)
+}
+
+func (c *ClientSuite) Test_DiskCacheStore() {
+	c.T().Parallel()
+	ctx := context.Background()
+	dir := filepath.Join(c.T().TempDir(), "cache")
+	store := NewDiskCacheStore(dir)
+
+	_, ok, err := store.Get(ctx, "https://example.com/items")
+	c.Require().NoError(err)
+	c.Require().False(ok)
+
+	c.Require().NoError(store.Set(ctx, "https://example.com/items", []byte("entry")))
+	value, ok, err := store.Get(ctx, "https://example.com/items")
+	c.Require().NoError(err)
+	c.Require().True(ok)
+	c.Require().Equal([]byte("entry"), value)
+
+	files, err := os.ReadDir(dir)
+	c.Require().NoError(err)
+	c.Require().Len(files, 1)
+
+	c.Require().NoError(store.Delete(ctx, "https://example.com/items"))
+	c.Require().NoError(store.Delete(ctx, "https://example.com/items"))
+	_, ok, _ = store.Get(ctx, "https://example.com/items")
+	c.Require().False(ok)
+}
@@ -0,0 +1,275 @@
+package inpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultConsecutiveFailures = 5
+	defaultMinimumCalls        = 10
+	defaultFailureWindow       = time.Minute
+	defaultOpenTimeout         = 30 * time.Second
+)
+
+// CircuitState is the state of a circuit of CircuitBreakerMiddleware.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all the requests through and counts the failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails all the requests with ErrCircuitOpen until OpenTimeout passes.
+	CircuitOpen
+	// CircuitHalfOpen lets HalfOpenMaxCalls trial requests through, they decide whether the circuit closes or opens again.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitStateChange is passed to CircuitBreakerConfig.OnStateChange when a circuit changes its state.
+type CircuitStateChange struct {
+	Key  string
+	From CircuitState
+	To   CircuitState
+}
+
+type CircuitBreakerConfig struct {
+	// KeyFunc returns the key of the circuit a request belongs to. Default is the host of the request.
+	KeyFunc func(req *http.Request) string
+	// ConsecutiveFailures opens the circuit after that many failures in a row.
+	// Default is 5 when FailureRatio is not set either.
+	ConsecutiveFailures int
+	// FailureRatio opens the circuit when the ratio of the failures in FailureWindow reaches it, between 0 and 1.
+	FailureRatio float64
+	// MinimumCalls is the number of calls in FailureWindow before FailureRatio is checked. Default is 10.
+	MinimumCalls int
+	// FailureWindow is the period the calls are counted for FailureRatio. Default is 1 minute.
+	FailureWindow time.Duration
+	// SlowCallThreshold counts a call that takes longer than it as a failure. Zero disables it.
+	SlowCallThreshold time.Duration
+	// OpenTimeout is how long the circuit stays open before it lets trial requests through. Default is 30 seconds.
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls is the number of trial requests in the half-open state, all of them must succeed to close
+	// the circuit. Default is 1.
+	HalfOpenMaxCalls int
+	// IsFailure reports whether the result of a call is a failure. By default, connection errors and the
+	// statuses retried by RetryMiddleware (5xx and 429) are failures, a cancelled context is not.
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange is called after a circuit changes its state.
+	OnStateChange func(change CircuitStateChange)
+}
+
+type circuitBreakerMiddleware struct {
+	config   CircuitBreakerConfig
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuitBreakerTransport struct {
+	*circuitBreakerMiddleware
+	next http.RoundTripper
+}
+
+type circuit struct {
+	state               CircuitState
+	openedAt            time.Time
+	windowStart         time.Time
+	calls               int
+	failures            int
+	consecutiveFailures int
+	halfOpenCalls       int
+	halfOpenSuccesses   int
+}
+
+// CircuitBreakerMiddleware creates a middleware that stops sending requests to a host after it fails repeatedly,
+// so a dependency that is down is not hammered by the retries. While the circuit of a host is open, requests fail
+// with ErrCircuitOpen without being sent. The state is shared by all the clients that use the middleware.
+// Usage:
+//
+//	client := New().Use(
+//		RetryMiddleware(3),
+//		CircuitBreakerMiddleware(CircuitBreakerConfig{FailureRatio: 0.5, OpenTimeout: 10 * time.Second}),
+//	)
+func CircuitBreakerMiddleware(config CircuitBreakerConfig) Middleware {
+	if config.ConsecutiveFailures <= 0 && config.FailureRatio <= 0 {
+		config.ConsecutiveFailures = defaultConsecutiveFailures
+	}
+	if config.MinimumCalls <= 0 {
+		config.MinimumCalls = defaultMinimumCalls
+	}
+	if config.FailureWindow <= 0 {
+		config.FailureWindow = defaultFailureWindow
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaultOpenTimeout
+	}
+	if config.HalfOpenMaxCalls <= 0 {
+		config.HalfOpenMaxCalls = 1
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = KeyByHost
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isCircuitFailure
+	}
+
+	return &circuitBreakerMiddleware{
+		config:   config,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+func (t *circuitBreakerMiddleware) ID() string {
+	return "circuit-breaker-middleware"
+}
+
+func (t *circuitBreakerMiddleware) Priority() int {
+	return 20
+}
+
+func (t *circuitBreakerMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
+	return &circuitBreakerTransport{circuitBreakerMiddleware: t, next: next}
+}
+
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := t.config.KeyFunc(req)
+	clock := ExtractClockFromContext(req.Context())
+	if !t.allow(key, clock.Now()) {
+		return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, key)
+	}
+
+	start := clock.Now()
+	resp, err := t.next.RoundTrip(req)
+	failed := t.config.IsFailure(resp, err) ||
+		(t.config.SlowCallThreshold > 0 && clock.Now().Sub(start) > t.config.SlowCallThreshold)
+	t.record(key, failed, clock.Now())
+
+	return resp, err
+}
+
+func (t *circuitBreakerMiddleware) allow(key string, now time.Time) bool {
+	t.mu.Lock()
+	c := t.circuit(key, now)
+	from := c.state
+	if c.state == CircuitOpen && now.Sub(c.openedAt) >= t.config.OpenTimeout {
+		c.state = CircuitHalfOpen
+		c.halfOpenCalls = 0
+		c.halfOpenSuccesses = 0
+	}
+
+	allowed := true
+	switch c.state {
+	case CircuitOpen:
+		allowed = false
+	case CircuitHalfOpen:
+		if c.halfOpenCalls >= t.config.HalfOpenMaxCalls {
+			allowed = false
+		} else {
+			c.halfOpenCalls++
+		}
+	}
+	to := c.state
+	t.mu.Unlock()
+
+	t.notify(key, from, to)
+
+	return allowed
+}
+
+func (t *circuitBreakerMiddleware) record(key string, failed bool, now time.Time) {
+	t.mu.Lock()
+	c := t.circuit(key, now)
+	from := c.state
+
+	switch c.state {
+	case CircuitHalfOpen:
+		if failed {
+			c.open(now)
+		} else if c.halfOpenSuccesses++; c.halfOpenSuccesses >= t.config.HalfOpenMaxCalls {
+			c.close(now)
+		}
+	case CircuitClosed:
+		if now.Sub(c.windowStart) >= t.config.FailureWindow {
+			c.windowStart = now
+			c.calls = 0
+			c.failures = 0
+		}
+
+		c.calls++
+		if failed {
+			c.failures++
+			c.consecutiveFailures++
+		} else {
+			c.consecutiveFailures = 0
+		}
+
+		if t.shouldTrip(c) {
+			c.open(now)
+		}
+	}
+	to := c.state
+	t.mu.Unlock()
+
+	t.notify(key, from, to)
+}
+
+func (t *circuitBreakerMiddleware) shouldTrip(c *circuit) bool {
+	if t.config.ConsecutiveFailures > 0 && c.consecutiveFailures >= t.config.ConsecutiveFailures {
+		return true
+	}
+
+	return t.config.FailureRatio > 0 && c.calls >= t.config.MinimumCalls &&
+		float64(c.failures)/float64(c.calls) >= t.config.FailureRatio
+}
+
+func (t *circuitBreakerMiddleware) circuit(key string, now time.Time) *circuit {
+	c, ok := t.circuits[key]
+	if !ok {
+		c = &circuit{state: CircuitClosed, windowStart: now}
+		t.circuits[key] = c
+	}
+
+	return c
+}
+
+func (t *circuitBreakerMiddleware) notify(key string, from, to CircuitState) {
+	if from != to && t.config.OnStateChange != nil {
+		t.config.OnStateChange(CircuitStateChange{Key: key, From: from, To: to})
+	}
+}
+
+func (c *circuit) open(now time.Time) {
+	c.state = CircuitOpen
+	c.openedAt = now
+}
+
+func (c *circuit) close(now time.Time) {
+	c.state = CircuitClosed
+	c.windowStart = now
+	c.calls = 0
+	c.failures = 0
+	c.consecutiveFailures = 0
+}
+
+func isCircuitFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return checkRetryBasedOnStatusCode(resp)
+}
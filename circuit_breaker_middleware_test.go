@@ -0,0 +1,174 @@
+package inpu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/denizgursoy/inpu/inputest"
+)
+
+func newFlakyServer(status *atomic.Int32, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+}
+
+func (c *ClientSuite) Test_CircuitBreaker_Opens_After_Consecutive_Failures() {
+	c.T().Parallel()
+	var status, calls atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	server := newFlakyServer(&status, &calls)
+	defer server.Close()
+
+	clock := inputest.NewFakeClock(time.Now())
+	var mu sync.Mutex
+	changes := make([]CircuitStateChange, 0)
+	client := New().
+		BasePath(server.URL).
+		Clock(clock).
+		Use(CircuitBreakerMiddleware(CircuitBreakerConfig{
+			ConsecutiveFailures: 3,
+			OpenTimeout:         time.Minute,
+			OnStateChange: func(change CircuitStateChange) {
+				mu.Lock()
+				defer mu.Unlock()
+				changes = append(changes, change)
+			},
+		}))
+
+	for range 3 {
+		c.Require().NoError(client.Get("/").Send())
+	}
+
+	err := client.Get("/").Send()
+	c.Require().ErrorIs(err, ErrCircuitOpen)
+	c.Require().ErrorIs(err, ErrConnectionFailed)
+	c.Require().EqualValues(3, calls.Load())
+
+	clock.Advance(time.Minute)
+	status.Store(http.StatusOK)
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().EqualValues(5, calls.Load())
+
+	key := server.Listener.Addr().String()
+	c.Require().Equal([]CircuitStateChange{
+		{Key: key, From: CircuitClosed, To: CircuitOpen},
+		{Key: key, From: CircuitOpen, To: CircuitHalfOpen},
+		{Key: key, From: CircuitHalfOpen, To: CircuitClosed},
+	}, changes)
+}
+
+func (c *ClientSuite) Test_CircuitBreaker_Reopens_When_Trial_Fails() {
+	c.T().Parallel()
+	var status, calls atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	server := newFlakyServer(&status, &calls)
+	defer server.Close()
+
+	clock := inputest.NewFakeClock(time.Now())
+	client := New().
+		BasePath(server.URL).
+		Clock(clock).
+		Use(CircuitBreakerMiddleware(CircuitBreakerConfig{ConsecutiveFailures: 1, OpenTimeout: time.Minute}))
+
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().ErrorIs(client.Get("/").Send(), ErrCircuitOpen)
+
+	clock.Advance(time.Minute)
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().ErrorIs(client.Get("/").Send(), ErrCircuitOpen)
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_CircuitBreaker_Failure_Ratio_Per_Host() {
+	c.T().Parallel()
+	var status, calls, healthyCalls atomic.Int32
+	status.Store(http.StatusOK)
+	server := newFlakyServer(&status, &calls)
+	defer server.Close()
+	var healthyStatus atomic.Int32
+	healthyStatus.Store(http.StatusOK)
+	healthy := newFlakyServer(&healthyStatus, &healthyCalls)
+	defer healthy.Close()
+
+	breaker := CircuitBreakerMiddleware(CircuitBreakerConfig{FailureRatio: 0.5, MinimumCalls: 4})
+	client := New().Use(breaker)
+
+	for i := range 4 {
+		if i%2 == 0 {
+			status.Store(http.StatusOK)
+		} else {
+			status.Store(http.StatusBadGateway)
+		}
+		c.Require().NoError(client.Get(server.URL).Send())
+	}
+
+	c.Require().ErrorIs(client.Get(server.URL).Send(), ErrCircuitOpen)
+	c.Require().NoError(New().Use(breaker).Get(healthy.URL).Send())
+	c.Require().EqualValues(1, healthyCalls.Load())
+}
+
+func (c *ClientSuite) Test_CircuitBreaker_Slow_Calls() {
+	c.T().Parallel()
+	clock := inputest.NewFakeClock(time.Now())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clock.Advance(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := New().
+		BasePath(server.URL).
+		Clock(clock).
+		Use(CircuitBreakerMiddleware(CircuitBreakerConfig{
+			SlowCallThreshold:   10 * time.Millisecond,
+			ConsecutiveFailures: 2,
+		}))
+
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().ErrorIs(client.Get("/").Send(), ErrCircuitOpen)
+}
+
+func (c *ClientSuite) Test_CircuitBreaker_Slow_Calls_With_Default_Threshold() {
+	c.T().Parallel()
+	clock := inputest.NewFakeClock(time.Now())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clock.Advance(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := New().
+		BasePath(server.URL).
+		Clock(clock).
+		Use(CircuitBreakerMiddleware(CircuitBreakerConfig{SlowCallThreshold: 5 * time.Millisecond}))
+
+	for range defaultConsecutiveFailures {
+		c.Require().NoError(client.Get("/").Send())
+	}
+	c.Require().ErrorIs(client.Get("/").Send(), ErrCircuitOpen)
+}
+
+func (c *ClientSuite) Test_CircuitBreaker_Is_Not_Retried() {
+	c.T().Parallel()
+	var status, calls atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	server := newFlakyServer(&status, &calls)
+	defer server.Close()
+
+	err := New().
+		BasePath(server.URL).
+		Use(
+			RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 5, InitialBackoff: time.Millisecond}),
+			CircuitBreakerMiddleware(CircuitBreakerConfig{ConsecutiveFailures: 2}),
+		).
+		Get("/").
+		Send()
+
+	c.Require().ErrorIs(err, ErrCircuitOpen)
+	c.Require().EqualValues(2, calls.Load())
+}
@@ -9,7 +9,6 @@ import (
 	"net/http/cookiejar"
 	netUrl "net/url"
 	"slices"
-	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -26,13 +25,21 @@ type Client struct {
 	userClient      *http.Client
 	basePath        string
 	mws             []Middleware
+	preparedMws     []Middleware
 	clientInit      sync.Once
 	tlsConfig       *tls.Config
 	isHttp2Disabled bool
 	isTlsDisabled   bool
 	baseTransport   *http.Transport
+	transport       http.RoundTripper
+	middlewareError error
+	hooks           hookSet
+	clock           Clock
 	ctx             context.Context
 	cancel          context.CancelFunc
+	replies         []replyBehavior
+	isStrict        bool
+	isMatchInOrder  bool
 }
 
 func New() *Client {
@@ -59,73 +66,132 @@ func NewWithContext(ctx context.Context) *Client {
 func (c *Client) Get(url string) *Req {
 	c.prepareClientOnce()
 
-	return getReq(c.ctx, url, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(getReq(c.ctx, url, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) GetCtx(ctx context.Context, url string) *Req {
 	c.prepareClientOnce()
 
-	return getReq(ctx, url, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(getReq(ctx, url, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) Post(url string, body Requester) *Req {
 	c.prepareClientOnce()
 
-	return postReq(c.ctx, url, body, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(postReq(c.ctx, url, body, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) PostCtx(ctx context.Context, url string, body Requester) *Req {
 	c.prepareClientOnce()
 
-	return postReq(ctx, url, body, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(postReq(ctx, url, body, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) Delete(url string, body Requester) *Req {
 	c.prepareClientOnce()
 
-	return deleteReq(c.ctx, url, body, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(deleteReq(c.ctx, url, body, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) DeleteCtx(ctx context.Context, url string, body Requester) *Req {
 	c.prepareClientOnce()
 
-	return deleteReq(ctx, url, body, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(deleteReq(ctx, url, body, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) Put(url string, body Requester) *Req {
 	c.prepareClientOnce()
 
-	return putReq(c.ctx, url, body, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(putReq(c.ctx, url, body, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) PutCtx(ctx context.Context, url string, body Requester) *Req {
 	c.prepareClientOnce()
 
-	return putReq(ctx, url, body, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(putReq(ctx, url, body, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) Patch(url string, body Requester) *Req {
 	c.prepareClientOnce()
 
-	return patchReq(c.ctx, url, body, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(patchReq(c.ctx, url, body, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) PatchCtx(ctx context.Context, url string, body Requester) *Req {
 	c.prepareClientOnce()
 
-	return patchReq(ctx, url, body, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(patchReq(ctx, url, body, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) Head(url string) *Req {
 	c.prepareClientOnce()
 
-	return headReq(c.ctx, url, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(headReq(c.ctx, url, c.headers, c.queries, c.userClient, c.basePath))
 }
 
 func (c *Client) HeadCtx(ctx context.Context, url string) *Req {
 	c.prepareClientOnce()
 
-	return headReq(ctx, url, c.headers, c.queries, c.userClient, c.basePath)
+	return c.inherit(headReq(ctx, url, c.headers, c.queries, c.userClient, c.basePath))
+}
+
+// On adds a status matcher and response handler that every request created by the client inherits.
+// Request level handlers are checked before the client level ones on the same priority, so a request
+// can override the default reply of the client. See Req.On for the matching rules.
+// Usage:
+//
+//	client := New().
+//		OnUnauthorized(ThenReturnError(ErrSessionExpired)).
+//		OnAny(ThenReturnDefaultError)
+func (c *Client) On(statusMatcher StatusMatcher, responseHandler ResponseHandler) *Client {
+	c.replies = append(c.replies, replyBehavior{
+		statusMatcher:   statusMatcher,
+		responseHandler: responseHandler,
+	})
+
+	return c
+}
+
+// Strict makes Send return ErrUnhandledStatus when neither the request nor the client
+// has a status matcher for the response status. By default, an unmatched status returns nil.
+func (c *Client) Strict() *Client {
+	c.isStrict = true
+
+	return c
+}
+
+// MatchInOrder makes every request of the client check its status matchers in declaration order
+// instead of sorting them by priority. See Req.MatchInOrder.
+func (c *Client) MatchInOrder() *Client {
+	c.isMatchInOrder = true
+
+	return c
+}
+
+// Hooks adds lifecycle hooks to the requests of the client. It can be called many times, all the hooks are called
+// in the order they are added. OnRequest, OnResponse and OnError are called for every attempt of a request.
+// Usage:
+//
+//	client := New().Hooks(Hooks{
+//		OnResponse: func(event ResponseEvent) {
+//			requestDuration.Observe(event.Duration.Seconds())
+//		},
+//	})
+func (c *Client) Hooks(hooks Hooks) *Client {
+	c.hooks = append(c.hooks, hooks)
+
+	return c
+}
+
+// Clock sets the clock of the time-based middlewares for every request of the client, see Clock.
+// Usage:
+//
+//	clock := inputest.NewFakeClock(time.Now())
+//	client := New().Clock(clock).Use(RetryMiddleware(3))
+func (c *Client) Clock(clock Clock) *Client {
+	c.clock = clock
+
+	return c
 }
 
 func (c *Client) Header(key, val string) *Client {
@@ -134,25 +200,28 @@ func (c *Client) Header(key, val string) *Client {
 	return c
 }
 
+// Use adds middlewares to the client. The chain of the client is built on the first request, the middlewares added
+// after it are ignored.
 func (c *Client) Use(mws ...Middleware) *Client {
-	for i := range mws {
-		if mws[i] == nil {
-			continue
-		}
-		index := slices.IndexFunc(c.mws, func(m Middleware) bool {
-			return m.ID() == mws[i].ID()
-		})
-
-		if index != -1 {
-			c.mws = append(c.mws[:index], c.mws[index+1:]...)
-		}
-
-		c.mws = append(c.mws, mws[i])
-	}
+	c.mws = addMiddlewares(c.mws, mws...)
 
 	return c
 }
 
+// Middlewares returns the middlewares of the client in the order they see a request.
+// It returns ErrMiddlewareCycle if the order constraints contradict each other.
+// Usage:
+//
+//	chain, err := client.Middlewares()
+//	fmt.Println(chain)
+//	// request
+//	//   -> request-modifier-middleware (priority 100)
+//	//   -> retry-middleware (priority 25)
+//	//   -> transport
+func (c *Client) Middlewares() (MiddlewareChain, error) {
+	return resolveMiddlewares(c.mws)
+}
+
 func (c *Client) DisableRedirects() *Client {
 	c.configureRedirects(0)
 
@@ -188,17 +257,17 @@ func (c *Client) FollowRedirects(maxRedirect int) *Client {
 func (c *Client) configureRedirects(maxRedirect int) {
 	if maxRedirect <= 0 {
 		// Disable automatic redirects
-		c.userClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		c.userClient.CheckRedirect = checkRedirectWithHooks(func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
-		}
+		})
 	} else {
 		// Custom redirect policy
-		c.userClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		c.userClient.CheckRedirect = checkRedirectWithHooks(func(req *http.Request, via []*http.Request) error {
 			if len(via) >= maxRedirect {
-				return fmt.Errorf("stopped after %d redirects", maxRedirect)
+				return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, maxRedirect)
 			}
 			return nil
-		}
+		})
 	}
 }
 
@@ -462,6 +531,12 @@ func (c *Client) BasePath(basePath string) *Client {
 	return c
 }
 
+func (c *Client) inherit(req *Req) *Req {
+	req.client = c
+
+	return req
+}
+
 func (c *Client) prepareClientOnce() {
 	c.clientInit.Do(func() {
 		if c.userClient.Transport == nil {
@@ -485,13 +560,20 @@ func (c *Client) prepareClientOnce() {
 			transport.TLSNextProto = make(map[string]func(authority string, c *tls.Conn) http.RoundTripper) // Disable HTTP/2
 		}
 
-		sort.SliceStable(c.mws, func(i, j int) bool {
-			return c.mws[i].Priority() < c.mws[j].Priority()
-		})
+		// the hooks come from the context of the request, so the hooks added after this call are observed too
+		c.transport = &hooksTransport{next: c.userClient.Transport}
+		if c.userClient.CheckRedirect == nil {
+			c.userClient.CheckRedirect = checkRedirectWithHooks(nil)
+		}
+		// the requests with their own middlewares build their chain from the same middlewares as the client
+		c.preparedMws = slices.Clone(c.mws)
+		chain, err := chainMiddlewares(c.transport, c.preparedMws)
+		if err != nil {
+			c.middlewareError = err
 
-		for i := range c.mws {
-			c.userClient.Transport = c.mws[i].Apply(c.userClient.Transport)
+			return
 		}
+		c.userClient.Transport = chain
 	})
 }
 
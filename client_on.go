@@ -0,0 +1,457 @@
+package inpu
+
+// OnOneOf is a shorthand for On(StatusIsOneOf(statusCodes...), responseHandler).
+// It matches any of the provided status codes.
+// Usage:
+//
+//	New().OnOneOf(ThenDoNothing, http.StatusOK, http.StatusCreated)
+func (c *Client) OnOneOf(responseHandler ResponseHandler, statusCodes ...int) *Client {
+	return c.On(StatusIsOneOf(statusCodes...), responseHandler)
+}
+
+// OnAny is a shorthand for On(StatusAny, responseHandler).
+// It matches any status code. Useful as a fallback.
+// Usage:
+//
+//	New().OnAny(ThenReturnDefaultError)
+func (c *Client) OnAny(responseHandler ResponseHandler) *Client {
+	return c.On(StatusAny, responseHandler)
+}
+
+// OnAnyExcept is a shorthand for On(StatusAnyExcept(statusCode), responseHandler).
+// It matches any status code except the one provided.
+// Usage:
+//
+//	New().OnAnyExcept(http.StatusOK, ThenReturnDefaultError)
+func (c *Client) OnAnyExcept(statusCode int, responseHandler ResponseHandler) *Client {
+	return c.On(StatusAnyExcept(statusCode), responseHandler)
+}
+
+// OnAnyExceptOneOf is a shorthand for On(StatusAnyExceptOneOf(statusCodes...), responseHandler).
+// It matches any status code except those provided.
+// Usage:
+//
+//	New().OnAnyExceptOneOf(ThenReturnDefaultError, http.StatusOK, http.StatusCreated)
+func (c *Client) OnAnyExceptOneOf(responseHandler ResponseHandler, statusCodes ...int) *Client {
+	return c.On(StatusAnyExceptOneOf(statusCodes...), responseHandler)
+}
+
+// --- Category matchers ---
+
+// OnSuccess is a shorthand for On(StatusIsSuccess, responseHandler).
+// It matches any status code in the range [200, 300).
+// Usage:
+//
+//	New().OnSuccess(ThenDoNothing)
+func (c *Client) OnSuccess(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsSuccess, responseHandler)
+}
+
+// OnInformational is a shorthand for On(StatusIsInformational, responseHandler).
+// It matches any status code less than 200.
+func (c *Client) OnInformational(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsInformational, responseHandler)
+}
+
+// OnRedirection is a shorthand for On(StatusIsRedirection, responseHandler).
+// It matches any status code in the range [300, 400).
+func (c *Client) OnRedirection(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsRedirection, responseHandler)
+}
+
+// OnClientError is a shorthand for On(StatusIsClientError, responseHandler).
+// It matches any status code in the range [400, 500).
+func (c *Client) OnClientError(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsClientError, responseHandler)
+}
+
+// OnServerError is a shorthand for On(StatusIsServerError, responseHandler).
+// It matches any status code greater than or equal to 500.
+func (c *Client) OnServerError(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsServerError, responseHandler)
+}
+
+// --- 1xx Informational ---
+
+// OnContinue is a shorthand for On(StatusIsContinue, responseHandler).
+// It matches status code 100.
+func (c *Client) OnContinue(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsContinue, responseHandler)
+}
+
+// OnSwitchingProtocols is a shorthand for On(StatusIsSwitchingProtocols, responseHandler).
+// It matches status code 101.
+func (c *Client) OnSwitchingProtocols(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsSwitchingProtocols, responseHandler)
+}
+
+// OnProcessing is a shorthand for On(StatusIsProcessing, responseHandler).
+// It matches status code 102.
+func (c *Client) OnProcessing(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsProcessing, responseHandler)
+}
+
+// OnEarlyHints is a shorthand for On(StatusIsEarlyHints, responseHandler).
+// It matches status code 103.
+func (c *Client) OnEarlyHints(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsEarlyHints, responseHandler)
+}
+
+// --- 2xx Success ---
+
+// OnOk is a shorthand for On(StatusIsOk, responseHandler).
+// It matches status code 200.
+// Usage:
+//
+//	New().OnOk(ThenDoNothing)
+func (c *Client) OnOk(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsOk, responseHandler)
+}
+
+// OnCreated is a shorthand for On(StatusIsCreated, responseHandler).
+// It matches status code 201.
+func (c *Client) OnCreated(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsCreated, responseHandler)
+}
+
+// OnAccepted is a shorthand for On(StatusIsAccepted, responseHandler).
+// It matches status code 202.
+func (c *Client) OnAccepted(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsAccepted, responseHandler)
+}
+
+// OnNonAuthoritativeInfo is a shorthand for On(StatusIsNonAuthoritativeInfo, responseHandler).
+// It matches status code 203.
+func (c *Client) OnNonAuthoritativeInfo(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsNonAuthoritativeInfo, responseHandler)
+}
+
+// OnNoContent is a shorthand for On(StatusIsNoContent, responseHandler).
+// It matches status code 204.
+func (c *Client) OnNoContent(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsNoContent, responseHandler)
+}
+
+// OnResetContent is a shorthand for On(StatusIsResetContent, responseHandler).
+// It matches status code 205.
+func (c *Client) OnResetContent(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsResetContent, responseHandler)
+}
+
+// OnPartialContent is a shorthand for On(StatusIsPartialContent, responseHandler).
+// It matches status code 206.
+func (c *Client) OnPartialContent(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsPartialContent, responseHandler)
+}
+
+// OnMultiStatus is a shorthand for On(StatusIsMultiStatus, responseHandler).
+// It matches status code 207.
+func (c *Client) OnMultiStatus(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsMultiStatus, responseHandler)
+}
+
+// OnAlreadyReported is a shorthand for On(StatusIsAlreadyReported, responseHandler).
+// It matches status code 208.
+func (c *Client) OnAlreadyReported(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsAlreadyReported, responseHandler)
+}
+
+// OnIMUsed is a shorthand for On(StatusIsIMUsed, responseHandler).
+// It matches status code 226.
+func (c *Client) OnIMUsed(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsIMUsed, responseHandler)
+}
+
+// --- 3xx Redirection ---
+
+// OnMultipleChoices is a shorthand for On(StatusIsMultipleChoices, responseHandler).
+// It matches status code 300.
+func (c *Client) OnMultipleChoices(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsMultipleChoices, responseHandler)
+}
+
+// OnMovedPermanently is a shorthand for On(StatusIsMovedPermanently, responseHandler).
+// It matches status code 301.
+func (c *Client) OnMovedPermanently(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsMovedPermanently, responseHandler)
+}
+
+// OnFound is a shorthand for On(StatusIsFound, responseHandler).
+// It matches status code 302.
+func (c *Client) OnFound(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsFound, responseHandler)
+}
+
+// OnSeeOther is a shorthand for On(StatusIsSeeOther, responseHandler).
+// It matches status code 303.
+func (c *Client) OnSeeOther(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsSeeOther, responseHandler)
+}
+
+// OnNotModified is a shorthand for On(StatusIsNotModified, responseHandler).
+// It matches status code 304.
+func (c *Client) OnNotModified(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsNotModified, responseHandler)
+}
+
+// OnUseProxy is a shorthand for On(StatusIsUseProxy, responseHandler).
+// It matches status code 305.
+func (c *Client) OnUseProxy(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsUseProxy, responseHandler)
+}
+
+// OnTemporaryRedirect is a shorthand for On(StatusIsTemporaryRedirect, responseHandler).
+// It matches status code 307.
+func (c *Client) OnTemporaryRedirect(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsTemporaryRedirect, responseHandler)
+}
+
+// OnPermanentRedirect is a shorthand for On(StatusIsPermanentRedirect, responseHandler).
+// It matches status code 308.
+func (c *Client) OnPermanentRedirect(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsPermanentRedirect, responseHandler)
+}
+
+// --- 4xx Client Errors ---
+
+// OnBadRequest is a shorthand for On(StatusIsBadRequest, responseHandler).
+// It matches status code 400.
+func (c *Client) OnBadRequest(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsBadRequest, responseHandler)
+}
+
+// OnUnauthorized is a shorthand for On(StatusIsUnauthorized, responseHandler).
+// It matches status code 401.
+func (c *Client) OnUnauthorized(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsUnauthorized, responseHandler)
+}
+
+// OnPaymentRequired is a shorthand for On(StatusIsPaymentRequired, responseHandler).
+// It matches status code 402.
+func (c *Client) OnPaymentRequired(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsPaymentRequired, responseHandler)
+}
+
+// OnForbidden is a shorthand for On(StatusIsForbidden, responseHandler).
+// It matches status code 403.
+func (c *Client) OnForbidden(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsForbidden, responseHandler)
+}
+
+// OnNotFound is a shorthand for On(StatusIsNotFound, responseHandler).
+// It matches status code 404.
+func (c *Client) OnNotFound(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsNotFound, responseHandler)
+}
+
+// OnMethodNotAllowed is a shorthand for On(StatusIsMethodNotAllowed, responseHandler).
+// It matches status code 405.
+func (c *Client) OnMethodNotAllowed(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsMethodNotAllowed, responseHandler)
+}
+
+// OnNotAcceptable is a shorthand for On(StatusIsNotAcceptable, responseHandler).
+// It matches status code 406.
+func (c *Client) OnNotAcceptable(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsNotAcceptable, responseHandler)
+}
+
+// OnProxyAuthRequired is a shorthand for On(StatusIsProxyAuthRequired, responseHandler).
+// It matches status code 407.
+func (c *Client) OnProxyAuthRequired(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsProxyAuthRequired, responseHandler)
+}
+
+// OnRequestTimeout is a shorthand for On(StatusIsRequestTimeout, responseHandler).
+// It matches status code 408.
+func (c *Client) OnRequestTimeout(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsRequestTimeout, responseHandler)
+}
+
+// OnConflict is a shorthand for On(StatusIsConflict, responseHandler).
+// It matches status code 409.
+func (c *Client) OnConflict(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsConflict, responseHandler)
+}
+
+// OnGone is a shorthand for On(StatusIsGone, responseHandler).
+// It matches status code 410.
+func (c *Client) OnGone(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsGone, responseHandler)
+}
+
+// OnLengthRequired is a shorthand for On(StatusIsLengthRequired, responseHandler).
+// It matches status code 411.
+func (c *Client) OnLengthRequired(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsLengthRequired, responseHandler)
+}
+
+// OnPreconditionFailed is a shorthand for On(StatusIsPreconditionFailed, responseHandler).
+// It matches status code 412.
+func (c *Client) OnPreconditionFailed(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsPreconditionFailed, responseHandler)
+}
+
+// OnRequestEntityTooLarge is a shorthand for On(StatusIsRequestEntityTooLarge, responseHandler).
+// It matches status code 413.
+func (c *Client) OnRequestEntityTooLarge(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsRequestEntityTooLarge, responseHandler)
+}
+
+// OnRequestURITooLong is a shorthand for On(StatusIsRequestURITooLong, responseHandler).
+// It matches status code 414.
+func (c *Client) OnRequestURITooLong(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsRequestURITooLong, responseHandler)
+}
+
+// OnUnsupportedMediaType is a shorthand for On(StatusIsUnsupportedMediaType, responseHandler).
+// It matches status code 415.
+func (c *Client) OnUnsupportedMediaType(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsUnsupportedMediaType, responseHandler)
+}
+
+// OnRequestedRangeNotSatisfiable is a shorthand for On(StatusIsRequestedRangeNotSatisfiable, responseHandler).
+// It matches status code 416.
+func (c *Client) OnRequestedRangeNotSatisfiable(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsRequestedRangeNotSatisfiable, responseHandler)
+}
+
+// OnExpectationFailed is a shorthand for On(StatusIsExpectationFailed, responseHandler).
+// It matches status code 417.
+func (c *Client) OnExpectationFailed(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsExpectationFailed, responseHandler)
+}
+
+// OnTeapot is a shorthand for On(StatusIsTeapot, responseHandler).
+// It matches status code 418.
+func (c *Client) OnTeapot(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsTeapot, responseHandler)
+}
+
+// OnMisdirectedRequest is a shorthand for On(StatusIsMisdirectedRequest, responseHandler).
+// It matches status code 421.
+func (c *Client) OnMisdirectedRequest(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsMisdirectedRequest, responseHandler)
+}
+
+// OnUnprocessableEntity is a shorthand for On(StatusIsUnprocessableEntity, responseHandler).
+// It matches status code 422.
+func (c *Client) OnUnprocessableEntity(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsUnprocessableEntity, responseHandler)
+}
+
+// OnLocked is a shorthand for On(StatusIsLocked, responseHandler).
+// It matches status code 423.
+func (c *Client) OnLocked(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsLocked, responseHandler)
+}
+
+// OnFailedDependency is a shorthand for On(StatusIsFailedDependency, responseHandler).
+// It matches status code 424.
+func (c *Client) OnFailedDependency(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsFailedDependency, responseHandler)
+}
+
+// OnTooEarly is a shorthand for On(StatusIsTooEarly, responseHandler).
+// It matches status code 425.
+func (c *Client) OnTooEarly(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsTooEarly, responseHandler)
+}
+
+// OnUpgradeRequired is a shorthand for On(StatusIsUpgradeRequired, responseHandler).
+// It matches status code 426.
+func (c *Client) OnUpgradeRequired(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsUpgradeRequired, responseHandler)
+}
+
+// OnPreconditionRequired is a shorthand for On(StatusIsPreconditionRequired, responseHandler).
+// It matches status code 428.
+func (c *Client) OnPreconditionRequired(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsPreconditionRequired, responseHandler)
+}
+
+// OnTooManyRequests is a shorthand for On(StatusIsTooManyRequests, responseHandler).
+// It matches status code 429.
+func (c *Client) OnTooManyRequests(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsTooManyRequests, responseHandler)
+}
+
+// OnRequestHeaderFieldsTooLarge is a shorthand for On(StatusIsRequestHeaderFieldsTooLarge, responseHandler).
+// It matches status code 431.
+func (c *Client) OnRequestHeaderFieldsTooLarge(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsRequestHeaderFieldsTooLarge, responseHandler)
+}
+
+// OnUnavailableForLegalReasons is a shorthand for On(StatusIsUnavailableForLegalReasons, responseHandler).
+// It matches status code 451.
+func (c *Client) OnUnavailableForLegalReasons(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsUnavailableForLegalReasons, responseHandler)
+}
+
+// --- 5xx Server Errors ---
+
+// OnInternalServerError is a shorthand for On(StatusIsInternalServerError, responseHandler).
+// It matches status code 500.
+func (c *Client) OnInternalServerError(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsInternalServerError, responseHandler)
+}
+
+// OnNotImplemented is a shorthand for On(StatusIsNotImplemented, responseHandler).
+// It matches status code 501.
+func (c *Client) OnNotImplemented(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsNotImplemented, responseHandler)
+}
+
+// OnBadGateway is a shorthand for On(StatusIsBadGateway, responseHandler).
+// It matches status code 502.
+func (c *Client) OnBadGateway(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsBadGateway, responseHandler)
+}
+
+// OnServiceUnavailable is a shorthand for On(StatusIsServiceUnavailable, responseHandler).
+// It matches status code 503.
+func (c *Client) OnServiceUnavailable(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsServiceUnavailable, responseHandler)
+}
+
+// OnGatewayTimeout is a shorthand for On(StatusIsGatewayTimeout, responseHandler).
+// It matches status code 504.
+func (c *Client) OnGatewayTimeout(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsGatewayTimeout, responseHandler)
+}
+
+// OnHTTPVersionNotSupported is a shorthand for On(StatusIsHTTPVersionNotSupported, responseHandler).
+// It matches status code 505.
+func (c *Client) OnHTTPVersionNotSupported(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsHTTPVersionNotSupported, responseHandler)
+}
+
+// OnVariantAlsoNegotiates is a shorthand for On(StatusIsVariantAlsoNegotiates, responseHandler).
+// It matches status code 506.
+func (c *Client) OnVariantAlsoNegotiates(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsVariantAlsoNegotiates, responseHandler)
+}
+
+// OnInsufficientStorage is a shorthand for On(StatusIsInsufficientStorage, responseHandler).
+// It matches status code 507.
+func (c *Client) OnInsufficientStorage(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsInsufficientStorage, responseHandler)
+}
+
+// OnLoopDetected is a shorthand for On(StatusIsLoopDetected, responseHandler).
+// It matches status code 508.
+func (c *Client) OnLoopDetected(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsLoopDetected, responseHandler)
+}
+
+// OnNotExtended is a shorthand for On(StatusIsNotExtended, responseHandler).
+// It matches status code 510.
+func (c *Client) OnNotExtended(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsNotExtended, responseHandler)
+}
+
+// OnNetworkAuthenticationRequired is a shorthand for On(StatusIsNetworkAuthenticationRequired, responseHandler).
+// It matches status code 511.
+func (c *Client) OnNetworkAuthenticationRequired(responseHandler ResponseHandler) *Client {
+	return c.On(StatusIsNetworkAuthenticationRequired, responseHandler)
+}
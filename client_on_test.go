@@ -0,0 +1,95 @@
+package inpu
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (c *ClientSuite) Test_Client_On_Is_Inherited() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	expectedErr := errors.New("unauthorized")
+	client := New().
+		OnUnauthorized(ThenReturnError(expectedErr))
+
+	err := client.Get(server.URL).
+		OnOk(ThenDoNothing).
+		Send()
+
+	c.Require().ErrorIs(err, expectedErr)
+}
+
+func (c *ClientSuite) Test_Request_On_Overrides_Client_On_With_Same_Priority() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	clientErr := errors.New("client level")
+	requestErr := errors.New("request level")
+	client := New().
+		OnNotFound(ThenReturnError(clientErr))
+
+	err := client.Get(server.URL).
+		OnNotFound(ThenReturnError(requestErr)).
+		Send()
+
+	c.Require().ErrorIs(err, requestErr)
+}
+
+func (c *ClientSuite) Test_Client_On_With_Higher_Priority_Wins() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	clientErr := errors.New("client level")
+	client := New().
+		OnUnauthorized(ThenReturnError(clientErr))
+
+	err := client.Get(server.URL).
+		OnAny(ThenDoNothing).
+		Send()
+
+	c.Require().ErrorIs(err, clientErr)
+}
+
+func (c *ClientSuite) Test_Client_Strict_Returns_Unhandled_Status() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := New().
+		Strict().
+		Get(server.URL).
+		OnOk(ThenDoNothing).
+		Send()
+
+	c.Require().ErrorIs(err, ErrUnhandledStatus)
+	var defaultError *DefaultError
+	c.Require().ErrorAs(err, &defaultError)
+}
+
+func (c *ClientSuite) Test_Client_Not_Strict_Ignores_Unhandled_Status() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := New().
+		Get(server.URL).
+		OnOk(ThenDoNothing).
+		Send()
+
+	c.Require().NoError(err)
+}
@@ -192,7 +192,7 @@ func (c *ClientSuite) Test_Client_Use_The_Last_Provided_Tls_Config() {
 	client.Get("/")
 
 	c.Require().Equal(client.tlsConfig, expectedTlsConfig)
-	c.Require().Equal(client.userClient.Transport.(*http.Transport).TLSClientConfig, expectedTlsConfig)
+	c.Require().Equal(client.transport.(*hooksTransport).next.(*http.Transport).TLSClientConfig, expectedTlsConfig)
 }
 
 func (c *ClientSuite) Test_Client_No_Redirect() {
@@ -0,0 +1,45 @@
+package inpu
+
+import (
+	"context"
+	"time"
+)
+
+const ContextKeyClock = "inpu_clock"
+
+// Clock tells the time to the time-based parts of the client: the retry waits and Retry-After dates, the cache
+// freshness, the rate limiter, the circuit breaker and the token refresh. A fake clock, like inputest.FakeClock,
+// makes their tests fast and deterministic.
+type Clock interface {
+	Now() time.Time
+	// After sends the time on the returned channel once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the real clock, it is used when no clock is set.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// ContextWithClock sets the clock of the middlewares, Client.Clock sets it for every request of the client.
+func ContextWithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, ContextKeyClock, clock)
+}
+
+// ExtractClockFromContext returns the clock of the request, SystemClock if none is set.
+func ExtractClockFromContext(ctx context.Context) Clock {
+	clock, ok := ctx.Value(ContextKeyClock).(Clock)
+	if !ok {
+		return SystemClock
+	}
+
+	return clock
+}
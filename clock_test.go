@@ -0,0 +1,89 @@
+package inpu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/denizgursoy/inpu/inputest"
+)
+
+func (c *ClientSuite) Test_Clock_Retry_After_Date() {
+	c.T().Parallel()
+	clock := inputest.NewFakeClock(time.Now())
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set(HeaderRetryAfter, clock.Now().Add(10*time.Minute).UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New().
+		BasePath(server.URL).
+		Clock(clock).
+		Use(RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 1, MaxBackoff: time.Hour}))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Get("/").OnOk(ThenDoNothing).Send()
+	}()
+
+	clock.BlockUntil(1)
+	c.Require().EqualValues(1, calls.Load())
+	clock.Advance(10 * time.Minute)
+
+	c.Require().NoError(<-done)
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_Clock_Cache_Staleness() {
+	c.T().Parallel()
+	clock := inputest.NewFakeClock(time.Now())
+	var calls atomic.Int32
+	server := newCacheServer(&calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderCacheControl, "max-age=60")
+	})
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Clock(clock).Use(CacheMiddleware(NewMemoryCacheStore(10)))
+
+	c.Require().NoError(client.Get("/").Send())
+	clock.Advance(59 * time.Second)
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().EqualValues(1, calls.Load())
+
+	clock.Advance(2 * time.Second)
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_Clock_CircuitBreaker_Open_Timeout() {
+	c.T().Parallel()
+	clock := inputest.NewFakeClock(time.Now())
+	var status, calls atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	server := newFlakyServer(&status, &calls)
+	defer server.Close()
+
+	client := New().
+		BasePath(server.URL).
+		Clock(clock).
+		Use(CircuitBreakerMiddleware(CircuitBreakerConfig{ConsecutiveFailures: 1, OpenTimeout: time.Minute}))
+
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().ErrorIs(client.Get("/").Send(), ErrCircuitOpen)
+
+	clock.Advance(59 * time.Second)
+	c.Require().ErrorIs(client.Get("/").Send(), ErrCircuitOpen)
+
+	clock.Advance(time.Second)
+	status.Store(http.StatusOK)
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().EqualValues(2, calls.Load())
+}
@@ -0,0 +1,184 @@
+package inpu
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// defaultCoalescingHeaders are the request headers that can change the response, so requests with different
+// values are never coalesced.
+var defaultCoalescingHeaders = []string{
+	HeaderAuthorization, HeaderCookie, HeaderAccept, HeaderAcceptEncoding, HeaderAcceptLanguage,
+}
+
+type CoalescingConfig struct {
+	// Headers are the request headers that are part of the key, requests with different values are sent separately.
+	// Default is Authorization, Cookie, Accept, Accept-Encoding and Accept-Language.
+	Headers []string
+}
+
+type coalescingMiddleware struct {
+	config CoalescingConfig
+	mu     sync.Mutex
+	calls  map[string]*coalescedCall
+}
+
+type coalescingTransport struct {
+	*coalescingMiddleware
+	next http.RoundTripper
+}
+
+// coalescedCall is an upstream call shared by the identical requests that arrived while it was in flight.
+type coalescedCall struct {
+	key     string
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	resp    *http.Response
+	body    []byte
+	err     error
+}
+
+// CoalescingMiddleware creates a middleware that collapses the concurrent identical GET and HEAD requests into one
+// upstream call and gives every caller a copy of the response, so an expired cache does not send the same request
+// hundreds of times. Requests are identical when they have the same method, URL and Headers.
+// A caller that cancels its context stops waiting, the shared call is only cancelled when no caller waits for it.
+// Usage:
+//
+//	client := New().Use(CoalescingMiddleware(CoalescingConfig{}))
+func CoalescingMiddleware(config CoalescingConfig) Middleware {
+	if len(config.Headers) == 0 {
+		config.Headers = defaultCoalescingHeaders
+	}
+
+	return &coalescingMiddleware{
+		config: config,
+		calls:  make(map[string]*coalescedCall),
+	}
+}
+
+func (t *coalescingMiddleware) ID() string {
+	return "coalescing-middleware"
+}
+
+func (t *coalescingMiddleware) Priority() int {
+	return 28
+}
+
+func (t *coalescingMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
+	return &coalescingTransport{coalescingMiddleware: t, next: next}
+}
+
+func (t *coalescingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) ||
+		(req.Body != nil && req.Body != http.NoBody) {
+		return t.next.RoundTrip(req)
+	}
+
+	call := t.join(req)
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+
+		return call.response(req), nil
+	case <-req.Context().Done():
+		t.leave(call)
+
+		return nil, req.Context().Err()
+	}
+}
+
+// join returns the call in flight for the request, or starts a new one.
+func (t *coalescingTransport) join(req *http.Request) *coalescedCall {
+	key := t.key(req)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if call, ok := t.calls[key]; ok {
+		call.waiters++
+
+		return call
+	}
+
+	// the shared call keeps the values of the first request but not its cancellation
+	ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+	call := &coalescedCall{key: key, done: make(chan struct{}), cancel: cancel, waiters: 1}
+	t.calls[key] = call
+
+	go t.do(call, req.Clone(ctx))
+
+	return call
+}
+
+func (t *coalescingTransport) do(call *coalescedCall, req *http.Request) {
+	defer call.cancel()
+
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		call.resp = resp
+		call.body, err = io.ReadAll(resp.Body)
+		if closeErr := resp.Body.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	call.err = err
+
+	t.mu.Lock()
+	t.forget(call)
+	t.mu.Unlock()
+
+	close(call.done)
+}
+
+// leave cancels the shared call when the last caller stops waiting for it.
+func (t *coalescingMiddleware) leave(call *coalescedCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	call.waiters--
+	if call.waiters == 0 {
+		call.cancel()
+		t.forget(call)
+	}
+}
+
+// forget removes the call so the next request starts a new one, t.mu must be held.
+func (t *coalescingMiddleware) forget(call *coalescedCall) {
+	if t.calls[call.key] == call {
+		delete(t.calls, call.key)
+	}
+}
+
+func (t *coalescingMiddleware) key(req *http.Request) string {
+	var key strings.Builder
+	key.WriteString(req.Method)
+	key.WriteString(" ")
+	key.WriteString(req.URL.String())
+	for _, name := range t.config.Headers {
+		key.WriteString("\n")
+		key.WriteString(name)
+		key.WriteString(": ")
+		key.WriteString(strings.Join(req.Header.Values(name), ", "))
+	}
+
+	return key.String()
+}
+
+// response returns a copy of the shared response with its own body.
+func (c *coalescedCall) response(req *http.Request) *http.Response {
+	resp := *c.resp
+	resp.Header = c.resp.Header.Clone()
+	resp.Trailer = c.resp.Trailer.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	resp.Request = req
+
+	return &resp
+}
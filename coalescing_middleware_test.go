@@ -0,0 +1,149 @@
+package inpu
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func newCoalescingServer(calls *atomic.Int32, release chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := calls.Add(1)
+		<-release
+		w.Header().Set(HeaderContentType, MimeTypeJson)
+		_, _ = fmt.Fprintf(w, `{"call":%d,"language":%q}`, call, r.Header.Get(HeaderAcceptLanguage))
+	}))
+}
+
+// waitForWaiters waits until n requests wait for the call in flight of the middleware.
+func (c *ClientSuite) waitForWaiters(middleware *coalescingMiddleware, n int) {
+	c.Require().Eventually(func() bool {
+		middleware.mu.Lock()
+		defer middleware.mu.Unlock()
+
+		waiters := 0
+		for _, call := range middleware.calls {
+			waiters += call.waiters
+		}
+
+		return waiters == n
+	}, time.Second, time.Millisecond)
+}
+
+func (c *ClientSuite) Test_Coalescing_Collapses_Identical_Requests() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := newCoalescingServer(&calls, release)
+	defer server.Close()
+
+	middleware := CoalescingMiddleware(CoalescingConfig{})
+	client := New().BasePath(server.URL).Use(middleware)
+
+	var wg sync.WaitGroup
+	results := make([]map[string]any, 20)
+	for i := range results {
+		wg.Go(func() {
+			results[i] = map[string]any{}
+			c.Require().NoError(client.Get("/").OnOk(ThenUnmarshalJsonTo(&results[i])).Send())
+		})
+	}
+
+	c.waitForWaiters(middleware.(*coalescingMiddleware), 20)
+	close(release)
+	wg.Wait()
+
+	c.Require().EqualValues(1, calls.Load())
+	for _, result := range results {
+		c.Require().EqualValues(1, result["call"])
+	}
+
+	// the call is forgotten once it completes
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_Coalescing_Keeps_Different_Headers_Apart() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := newCoalescingServer(&calls, release)
+	defer server.Close()
+
+	middleware := CoalescingMiddleware(CoalescingConfig{})
+	client := New().BasePath(server.URL).Use(middleware)
+
+	var wg sync.WaitGroup
+	languages := []string{"en", "fr", "en", "fr"}
+	results := make([]map[string]any, len(languages))
+	for i, language := range languages {
+		wg.Go(func() {
+			results[i] = map[string]any{}
+			c.Require().NoError(client.Get("/").
+				Header(HeaderAcceptLanguage, language).
+				OnOk(ThenUnmarshalJsonTo(&results[i])).
+				Send())
+		})
+	}
+
+	c.waitForWaiters(middleware.(*coalescingMiddleware), len(languages))
+	close(release)
+	wg.Wait()
+
+	c.Require().EqualValues(2, calls.Load())
+	for i, result := range results {
+		c.Require().Equal(languages[i], result["language"])
+	}
+}
+
+func (c *ClientSuite) Test_Coalescing_Cancelled_Waiter_Does_Not_Cancel_Others() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := newCoalescingServer(&calls, release)
+	defer server.Close()
+
+	middleware := CoalescingMiddleware(CoalescingConfig{})
+	client := New().BasePath(server.URL).Use(middleware)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		cancelled <- client.GetCtx(ctx, "/").Send()
+	}()
+	c.waitForWaiters(middleware.(*coalescingMiddleware), 1)
+
+	result := map[string]any{}
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Get("/").OnOk(ThenUnmarshalJsonTo(&result)).Send()
+	}()
+	c.waitForWaiters(middleware.(*coalescingMiddleware), 2)
+
+	cancel()
+	c.Require().ErrorIs(<-cancelled, context.Canceled)
+
+	close(release)
+	c.Require().NoError(<-done)
+	c.Require().EqualValues(1, result["call"])
+	c.Require().EqualValues(1, calls.Load())
+}
+
+func (c *ClientSuite) Test_Coalescing_Ignores_Unsafe_Methods() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	close(release)
+	server := newCoalescingServer(&calls, release)
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(CoalescingMiddleware(CoalescingConfig{}))
+
+	c.Require().NoError(client.Post("/", nil).Send())
+	c.Require().NoError(client.Post("/", nil).Send())
+	c.Require().EqualValues(2, calls.Load())
+}
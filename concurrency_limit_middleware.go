@@ -0,0 +1,231 @@
+package inpu
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const defaultMaxConcurrent = 10
+
+type ConcurrencyLimitConfig struct {
+	// MaxConcurrent is the number of requests of a key that can be in flight at the same time. Default is 10.
+	// A request is in flight until its response body is closed.
+	MaxConcurrent int
+	// MaxQueue is the number of requests of a key that can wait for a slot, the others fail with ErrBulkheadFull.
+	// Zero means the queue is not bounded.
+	MaxQueue int
+	// QueueTimeout makes a request fail with ErrBulkheadFull after waiting that long for a slot.
+	// Zero means waiting until the context of the request is done.
+	QueueTimeout time.Duration
+	// FailFast makes a request fail with ErrBulkheadFull right away when all the slots are taken.
+	FailFast bool
+	// KeyFunc returns the key a request is limited by. Default is the host of the request.
+	KeyFunc func(req *http.Request) string
+}
+
+type concurrencyLimitMiddleware struct {
+	config   ConcurrencyLimitConfig
+	mu       sync.Mutex
+	limiters map[string]*concurrencyLimiter
+}
+
+type concurrencyLimitTransport struct {
+	*concurrencyLimitMiddleware
+	next http.RoundTripper
+}
+
+// ConcurrencyLimitMiddleware creates a bulkhead middleware that limits the number of in-flight requests per host,
+// so a burst of goroutines cannot open hundreds of connections to one backend. The requests over the limit wait
+// in a FIFO queue. The queue depth and the wait time of every request are passed to Hooks.OnBulkhead.
+// The limits are shared by all the clients that use the middleware.
+// Usage:
+//
+//	client := New().Use(ConcurrencyLimitMiddleware(ConcurrencyLimitConfig{
+//		MaxConcurrent: 20,
+//		MaxQueue:      100,
+//		QueueTimeout:  time.Second,
+//	}))
+func ConcurrencyLimitMiddleware(config ConcurrencyLimitConfig) Middleware {
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = defaultMaxConcurrent
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = KeyByHost
+	}
+
+	return &concurrencyLimitMiddleware{
+		config:   config,
+		limiters: make(map[string]*concurrencyLimiter),
+	}
+}
+
+func (t *concurrencyLimitMiddleware) ID() string {
+	return "concurrency-limit-middleware"
+}
+
+func (t *concurrencyLimitMiddleware) Priority() int {
+	return 21
+}
+
+func (t *concurrencyLimitMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
+	return &concurrencyLimitTransport{concurrencyLimitMiddleware: t, next: next}
+}
+
+func (t *concurrencyLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := t.config.KeyFunc(req)
+	limiter := t.limiter(key)
+
+	start := time.Now()
+	queueDepth, err := limiter.acquire(req, t.config)
+	hooksFromContext(req.Context()).bulkhead(BulkheadEvent{
+		Request:    req,
+		Key:        key,
+		QueueDepth: queueDepth,
+		Wait:       time.Since(start),
+		Err:        err,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		limiter.release()
+
+		return resp, err
+	}
+
+	// the slot is taken until the body is consumed, the connection is in use until then
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: limiter.release}
+
+	return resp, nil
+}
+
+func (t *concurrencyLimitMiddleware) limiter(key string) *concurrencyLimiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limiter, ok := t.limiters[key]
+	if !ok {
+		limiter = newConcurrencyLimiter(t.config.MaxConcurrent)
+		t.limiters[key] = limiter
+	}
+
+	return limiter
+}
+
+// concurrencyLimiter is a semaphore with a FIFO queue.
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	waiters  []chan struct{}
+}
+
+func newConcurrencyLimiter(limit int) *concurrencyLimiter {
+	return &concurrencyLimiter{limit: limit}
+}
+
+// acquire takes a slot or waits for one in the queue. It returns the queue depth when the request joined it.
+func (l *concurrencyLimiter) acquire(req *http.Request, config ConcurrencyLimitConfig) (int, error) {
+	l.mu.Lock()
+	if l.inFlight < l.limit && len(l.waiters) == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+
+		return 0, nil
+	}
+
+	queueDepth := len(l.waiters)
+	if config.FailFast || (config.MaxQueue > 0 && queueDepth >= config.MaxQueue) {
+		l.mu.Unlock()
+
+		return queueDepth, fmt.Errorf("%w: %d requests in flight, %d in the queue", ErrBulkheadFull,
+			l.inFlight, queueDepth)
+	}
+
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if config.QueueTimeout > 0 {
+		timer := time.NewTimer(config.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-ready:
+		return queueDepth, nil
+	case <-req.Context().Done():
+		l.leave(ready)
+
+		return queueDepth, req.Context().Err()
+	case <-timeout:
+		l.leave(ready)
+
+		return queueDepth, fmt.Errorf("%w: waited %v in the queue", ErrBulkheadFull, config.QueueTimeout)
+	}
+}
+
+// leave removes the waiter from the queue, or gives back the slot if it was granted in the meantime.
+func (l *concurrencyLimiter) leave(ready chan struct{}) {
+	l.mu.Lock()
+	index := slices.Index(l.waiters, ready)
+	if index != -1 {
+		l.waiters = slices.Delete(l.waiters, index, index+1)
+		l.mu.Unlock()
+
+		return
+	}
+	l.mu.Unlock()
+
+	l.release()
+}
+
+func (l *concurrencyLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	l.grant()
+}
+
+// update changes the limit with the result of the function, the waiting requests are let through if it grows.
+func (l *concurrencyLimiter) update(newLimit func(limit, inFlight int) int) (int, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	previous := l.limit
+	l.limit = newLimit(l.limit, l.inFlight)
+	l.grant()
+
+	return previous, l.limit
+}
+
+func (l *concurrencyLimiter) grant() {
+	for l.inFlight < l.limit && len(l.waiters) > 0 {
+		l.inFlight++
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+	}
+}
+
+// releasingBody releases the slot of the request once when the body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+
+	return err
+}
@@ -0,0 +1,136 @@
+package inpu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// newBlockingServer answers the requests when release is closed, and reports the maximum concurrent requests.
+func newBlockingServer(release chan struct{}, arrived chan struct{}) (*httptest.Server, *atomic.Int32) {
+	var inFlight, maxInFlight atomic.Int32
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := maxInFlight.Load()
+			if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		arrived <- struct{}{}
+		<-release
+	})), &maxInFlight
+}
+
+// requireQueueLength waits until n requests to the server are waiting for a slot.
+func (c *ClientSuite) requireQueueLength(middleware Middleware, server *httptest.Server, n int) {
+	limiter := middleware.(*concurrencyLimitMiddleware).limiter(server.Listener.Addr().String())
+	c.Require().Eventually(func() bool {
+		limiter.mu.Lock()
+		defer limiter.mu.Unlock()
+
+		return len(limiter.waiters) == n
+	}, time.Second, time.Millisecond)
+}
+
+func (c *ClientSuite) Test_ConcurrencyLimit_Queues_Requests() {
+	c.T().Parallel()
+	release := make(chan struct{})
+	arrived := make(chan struct{}, 10)
+	server, maxInFlight := newBlockingServer(release, arrived)
+	defer server.Close()
+
+	var mu sync.Mutex
+	events := make([]BulkheadEvent, 0)
+	limit := ConcurrencyLimitMiddleware(ConcurrencyLimitConfig{MaxConcurrent: 2})
+	client := New().
+		BasePath(server.URL).
+		Use(limit).
+		Hooks(Hooks{
+			OnBulkhead: func(event BulkheadEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, event)
+			},
+		})
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			c.Require().NoError(client.Get("/").Send())
+		})
+	}
+
+	<-arrived
+	<-arrived
+	c.requireQueueLength(limit, server, 3)
+	close(release)
+	wg.Wait()
+
+	c.Require().EqualValues(2, maxInFlight.Load())
+	c.Require().Len(events, 5)
+	maxQueueDepth := 0
+	for _, event := range events {
+		c.Require().NoError(event.Err)
+		c.Require().Equal(server.Listener.Addr().String(), event.Key)
+		maxQueueDepth = max(maxQueueDepth, event.QueueDepth)
+	}
+	c.Require().Equal(2, maxQueueDepth)
+}
+
+func (c *ClientSuite) Test_ConcurrencyLimit_Fail_Fast() {
+	c.T().Parallel()
+	release := make(chan struct{})
+	arrived := make(chan struct{}, 10)
+	server, _ := newBlockingServer(release, arrived)
+	defer server.Close()
+
+	client := New().
+		BasePath(server.URL).
+		Use(ConcurrencyLimitMiddleware(ConcurrencyLimitConfig{MaxConcurrent: 1, FailFast: true}))
+
+	done := make(chan error)
+	go func() {
+		done <- client.Get("/").Send()
+	}()
+	<-arrived
+
+	c.Require().ErrorIs(client.Get("/").Send(), ErrBulkheadFull)
+	close(release)
+	c.Require().NoError(<-done)
+	c.Require().NoError(client.Get("/").Send())
+}
+
+func (c *ClientSuite) Test_ConcurrencyLimit_Bounded_Queue_And_Timeout() {
+	c.T().Parallel()
+	release := make(chan struct{})
+	arrived := make(chan struct{}, 10)
+	server, _ := newBlockingServer(release, arrived)
+	defer server.Close()
+
+	limit := ConcurrencyLimitMiddleware(ConcurrencyLimitConfig{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  50 * time.Millisecond,
+	})
+	client := New().BasePath(server.URL).Use(limit)
+
+	go client.Get("/").Send()
+	<-arrived
+
+	queued := make(chan error)
+	go func() {
+		queued <- client.Get("/").Send()
+	}()
+	c.requireQueueLength(limit, server, 1)
+
+	c.Require().ErrorIs(client.Get("/").Send(), ErrBulkheadFull)
+	err := <-queued
+	c.Require().ErrorIs(err, ErrBulkheadFull)
+	c.Require().ErrorContains(err, "waited 50ms in the queue")
+	close(release)
+}
@@ -17,7 +17,11 @@ type customMiddleware struct {
 	responseModifier ResponseModifier
 	middlewareID     string
 	priority         int
-	next             http.RoundTripper
+}
+
+type customTransport struct {
+	*customMiddleware
+	next http.RoundTripper
 }
 
 // CustomMiddleware creates a logging middleware
@@ -55,6 +59,19 @@ func RequestIDMiddleware() Middleware {
 	}, "request-modifier-middleware", 100)
 }
 
+// IdempotencyKeyMiddleware adds a HeaderIdempotencyKey to the POST and PATCH requests that do not have one.
+// The key is generated once per request and sent again by every retry attempt, so the server can deduplicate them
+// and RetryMiddleware retries the request.
+func IdempotencyKeyMiddleware() Middleware {
+	return RequestModifierMiddleware(func(req *http.Request) (*http.Request, error) {
+		if !isIdempotent(req) {
+			req.Header.Set(HeaderIdempotencyKey, uuid.New().String())
+		}
+
+		return req, nil
+	}, "idempotency-key-middleware", 90)
+}
+
 // ErrorHandlerMiddleware handles server errors
 func ErrorHandlerMiddleware(handler ErrorHandler) Middleware {
 	return newCustomMiddleware(nil, func(response *http.Response, serverError error) (*http.Response, error) {
@@ -75,12 +92,10 @@ func (t *customMiddleware) Priority() int {
 }
 
 func (t *customMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
-	t.next = next
-
-	return t
+	return &customTransport{customMiddleware: t, next: next}
 }
 
-func (t *customMiddleware) RoundTrip(req *http.Request) (*http.Response, error) {
+func (t *customTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	if t.requestModifier != nil {
 		modifiedRequest, err := t.requestModifier(req)
 		if err != nil {
@@ -0,0 +1,137 @@
+package inpu
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// ErrorClass tells whether sending a request again could succeed after it failed with an error.
+type ErrorClass int
+
+const (
+	// ErrorClassUnknown is an error ClassifyError does not know, like the error of a custom RoundTripper.
+	ErrorClassUnknown ErrorClass = iota
+	// ErrorClassTransient is an error that may not happen again, like a reset connection or a timeout.
+	ErrorClassTransient
+	// ErrorClassPermanent is an error that happens again for the same request, like an unknown host or an invalid
+	// certificate, or that must not be retried, like a request cancelled by the caller.
+	ErrorClassPermanent
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassUnknown:
+		return "unknown"
+	case ErrorClassTransient:
+		return "transient"
+	case ErrorClassPermanent:
+		return "permanent"
+	default:
+		return fmt.Sprintf("ErrorClass(%d)", int(c))
+	}
+}
+
+// ErrorClassifier classifies the error of a request that failed without a response.
+type ErrorClassifier func(err error) ErrorClass
+
+// transientErrnos are the connection errors a new connection may not have.
+var transientErrnos = []syscall.Errno{
+	syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED, syscall.EPIPE, syscall.ETIMEDOUT,
+}
+
+// ClassifyError is the default ErrorClassifier of RetryMiddleware, the other middlewares can use it to tell the
+// transient errors from the permanent ones.
+//
+// Permanent: a request cancelled by the caller, an unknown host, a TLS certificate or handshake failure, an invalid
+// URL, and the errors of the circuit breaker, the rate limit and the bulkhead, which would fail again at once.
+// Transient: reset, refused and aborted connections, timeouts, a connection closed by the server, HTTP/2 GOAWAY and
+// the other DNS errors.
+func ClassifyError(err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ErrorClassUnknown
+	case isPermanentError(err):
+		return ErrorClassPermanent
+	case isTransientError(err):
+		return ErrorClassTransient
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+func isPermanentError(err error) bool {
+	// a timeout of the attempt is DeadlineExceeded and can be retried, the caller cancelling the request cannot
+	if errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	// retrying would only hit the open circuit, the rate limit or the full bulkhead again
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrBulkheadFull) {
+		return true
+	}
+
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) && dnsError.IsNotFound {
+		return true
+	}
+
+	if isTLSError(err) {
+		return true
+	}
+
+	// the transport checks the URL before connecting, its errors are not exported
+	message := err.Error()
+
+	return strings.Contains(message, "unsupported protocol scheme") ||
+		strings.Contains(message, "no Host in request URL") ||
+		strings.Contains(message, "nil Request.URL")
+}
+
+func isTLSError(err error) bool {
+	var certificateVerificationError *tls.CertificateVerificationError
+	var recordHeaderError tls.RecordHeaderError
+	var alertError tls.AlertError
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalidError x509.CertificateInvalidError
+
+	return errors.As(err, &certificateVerificationError) || errors.As(err, &recordHeaderError) ||
+		errors.As(err, &alertError) || errors.As(err, &unknownAuthorityError) || errors.As(err, &hostnameError) ||
+		errors.As(err, &certificateInvalidError)
+}
+
+func isTransientError(err error) bool {
+	for _, errno := range transientErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+
+	var netError net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netError) && netError.Timeout()) {
+		return true
+	}
+
+	// the other DNS errors, like a timeout or a misbehaving server, are temporary
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		return true
+	}
+
+	// the server closed a pooled connection while the request was sent
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	// HTTP/2 GOAWAY and the closed idle connection errors are not exported
+	message := err.Error()
+
+	return strings.Contains(message, "GOAWAY") || strings.Contains(message, "server closed idle connection")
+}
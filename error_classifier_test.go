@@ -0,0 +1,92 @@
+package inpu
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+func (c *ClientSuite) Test_ClassifyError() {
+	c.T().Parallel()
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorClass
+	}{
+		{"connection reset", &net.OpError{Op: "read", Err: syscall.ECONNRESET}, ErrorClassTransient},
+		{"connection refused", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, ErrorClassTransient},
+		{"i/o timeout", &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, ErrorClassTransient},
+		{"attempt timeout", fmt.Errorf("attempt: %w", context.DeadlineExceeded), ErrorClassTransient},
+		{"closed pooled connection", io.EOF, ErrorClassTransient},
+		{"http2 goaway", errors.New("http2: server sent GOAWAY and closed the connection"), ErrorClassTransient},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}, ErrorClassTransient},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, ErrorClassPermanent},
+		{"bad certificate", x509.UnknownAuthorityError{}, ErrorClassPermanent},
+		{"caller cancellation", context.Canceled, ErrorClassPermanent},
+		{"malformed url", errors.New(`unsupported protocol scheme "htp"`), ErrorClassPermanent},
+		{"open circuit", fmt.Errorf("%w: example.com", ErrCircuitOpen), ErrorClassPermanent},
+		{"custom error", errors.New("custom"), ErrorClassUnknown},
+	}
+
+	for _, test := range tests {
+		c.Run(test.name, func() {
+			c.Require().Equal(test.expected, ClassifyError(test.err))
+		})
+	}
+}
+
+func (c *ClientSuite) Test_Retry_Skips_Permanent_Errors() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	failing := RequestModifierMiddleware(func(req *http.Request) (*http.Request, error) {
+		calls.Add(1)
+
+		return nil, &net.DNSError{Err: "no such host", Name: req.URL.Host, IsNotFound: true}
+	}, "failing-middleware", 0)
+
+	client := New().Use(RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond}), failing)
+
+	var dnsError *net.DNSError
+	c.Require().ErrorAs(client.Get("http://example.invalid").Send(), &dnsError)
+	c.Require().EqualValues(1, calls.Load())
+}
+
+func (c *ClientSuite) Test_Retry_Custom_Error_Classifier() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	errCustom := errors.New("custom")
+	var calls atomic.Int32
+	failing := RequestModifierMiddleware(func(req *http.Request) (*http.Request, error) {
+		calls.Add(1)
+
+		return nil, errCustom
+	}, "failing-middleware", 0)
+
+	client := New().BasePath(server.URL).Use(RetryMiddlewareWithConfig(RetryConfig{
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		ErrorClassifier: func(err error) ErrorClass {
+			if errors.Is(err, errCustom) {
+				return ErrorClassPermanent
+			}
+
+			return ClassifyError(err)
+		},
+	}), failing)
+
+	c.Require().ErrorIs(client.Get("/").Send(), errCustom)
+	c.Require().EqualValues(1, calls.Load())
+}
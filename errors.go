@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 var (
@@ -14,6 +15,11 @@ var (
 	ErrCouldNotParsePath     = errors.New("invalid path")
 	ErrMarshalToNil          = errors.New("cannot unmarshal to nil")
 	ErrNotPointerParameter   = errors.New("cannot marshal to non pointer type ")
+	ErrUnhandledStatus       = errors.New("no handler matched the status")
+	ErrMiddlewareCycle       = errors.New("middleware order constraints have a cycle")
+	ErrCircuitOpen           = errors.New("circuit breaker is open")
+	ErrRateLimited           = errors.New("rate limit is exceeded")
+	ErrBulkheadFull          = errors.New("too many requests in flight")
 )
 
 type DefaultError struct {
@@ -24,3 +30,48 @@ func (d *DefaultError) Error() string {
 	return fmt.Sprintf("called [%s] -> %s and got %d",
 		d.res.Request.Method, d.res.Request.URL.Redacted(), d.res.StatusCode)
 }
+
+// RetryAttempt is the result of an attempt of RetryMiddleware.
+type RetryAttempt struct {
+	// StatusCode is 0 when the attempt failed without a response.
+	StatusCode int
+	Err        error
+}
+
+func newRetryAttempt(resp *http.Response, err error) RetryAttempt {
+	attempt := RetryAttempt{Err: err}
+	if resp != nil {
+		attempt.StatusCode = resp.StatusCode
+	}
+
+	return attempt
+}
+
+// RetryExhaustedError is returned by RetryMiddleware when it stops retrying a request, because of MaxRetries, the
+// retry budget or the deadline of the request, and the last attempt failed without a response. When the last attempt
+// has a response, the response is returned so the status handlers can match it. It unwraps to the error of the last
+// attempt.
+type RetryExhaustedError struct {
+	Attempts []RetryAttempt
+}
+
+func (r *RetryExhaustedError) Error() string {
+	results := make([]string, 0, len(r.Attempts))
+	for i, attempt := range r.Attempts {
+		if attempt.Err != nil {
+			results = append(results, fmt.Sprintf("attempt %d: %v", i+1, attempt.Err))
+		} else {
+			results = append(results, fmt.Sprintf("attempt %d: status %d", i+1, attempt.StatusCode))
+		}
+	}
+
+	return fmt.Sprintf("all %d attempts failed: %s", len(r.Attempts), strings.Join(results, ", "))
+}
+
+func (r *RetryExhaustedError) Unwrap() error {
+	if len(r.Attempts) == 0 {
+		return nil
+	}
+
+	return r.Attempts[len(r.Attempts)-1].Err
+}
@@ -0,0 +1,231 @@
+package inpu
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const defaultETagMaxRetries = 3
+
+var (
+	ErrMissingETag        = errors.New("resource does not have an ETag")
+	ErrPreconditionFailed = errors.New("resource was modified concurrently")
+)
+
+// ETagOptions configures UpdateWithETag.
+type ETagOptions struct {
+	// Method is the method of the update request, http.MethodPut or http.MethodPatch. Default is http.MethodPut.
+	// With http.MethodPatch, a JSON Merge Patch (RFC 7386) of the changes is sent instead of the whole resource.
+	Method string
+	// MaxRetries is how many times the read-modify-write cycle is repeated after 412 Precondition Failed.
+	// Default is 3.
+	MaxRetries int
+}
+
+// UpdateWithETag fetches the JSON resource at url, applies mutate to it and writes it back with the If-Match header
+// set to the ETag of the fetched resource. If the resource is modified in the meantime and the server answers
+// 412 Precondition Failed, the cycle is repeated up to MaxRetries times, after that ErrPreconditionFailed is returned.
+// It returns the resource in the response of the update, or the mutated resource if the response has no body.
+// The requests are created with the client, so they inherit its configuration, middlewares and response handlers.
+// Usage:
+//
+//	item, err := UpdateWithETag(client, "/items/1", func(item *Item) error {
+//		item.Quantity++
+//		return nil
+//	}, ETagOptions{Method: http.MethodPatch})
+func UpdateWithETag[T any](client *Client, url string, mutate func(*T) error, opts ETagOptions) (*T, error) {
+	if opts.Method == "" {
+		opts.Method = http.MethodPut
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = defaultETagMaxRetries
+	}
+
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		updated, conflict, err := updateWithETag(client, url, mutate, opts)
+		if err != nil || !conflict {
+			return updated, err
+		}
+	}
+
+	return nil, fmt.Errorf("%w: gave up after %d retries", ErrPreconditionFailed, opts.MaxRetries)
+}
+
+func updateWithETag[T any](client *Client, url string, mutate func(*T) error, opts ETagOptions) (*T, bool, error) {
+	var resource T
+	var eTag string
+	err := etagRequest(client, http.MethodGet, url, nil).
+		OnOk(func(response *http.Response) error {
+			eTag = response.Header.Get(HeaderETag)
+
+			return ThenUnmarshalJsonTo(&resource)(response)
+		}).
+		OnAny(ThenReturnDefaultError).
+		Send()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if eTag == "" {
+		return nil, false, ErrMissingETag
+	}
+
+	original, err := jsonMarshal(resource)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrInvalidBody, err)
+	}
+
+	if err := mutate(&resource); err != nil {
+		return nil, false, err
+	}
+
+	body, contentType, err := etagUpdateBody(original, resource, opts.Method)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrInvalidBody, err)
+	}
+
+	var updated *T
+	conflict := false
+	err = etagRequest(client, opts.Method, url, BodyReader(bytes.NewReader(body))).
+		Header(HeaderIfMatch, eTag).
+		ContentType(contentType).
+		OnPreconditionFailed(func(_ *http.Response) error {
+			conflict = true
+
+			return nil
+		}).
+		OnSuccess(func(response *http.Response) error {
+			data, err := io.ReadAll(response.Body)
+			if err != nil {
+				return err
+			}
+
+			if len(bytes.TrimSpace(data)) == 0 {
+				updated = &resource
+
+				return nil
+			}
+
+			updated = new(T)
+
+			return jsonUnmarshalFromReader(bytes.NewReader(data), updated)
+		}).
+		OnAny(ThenReturnDefaultError).
+		Send()
+
+	return updated, conflict, err
+}
+
+func etagRequest(client *Client, method, url string, body Requester) *Req {
+	switch {
+	case method == http.MethodGet && client != nil:
+		return client.Get(url)
+	case method == http.MethodGet:
+		return Get(url)
+	case method == http.MethodPatch && client != nil:
+		return client.Patch(url, body)
+	case method == http.MethodPatch:
+		return Patch(url, body)
+	case client != nil:
+		return client.Put(url, body)
+	default:
+		return Put(url, body)
+	}
+}
+
+func etagUpdateBody(original []byte, resource any, method string) ([]byte, string, error) {
+	modified, err := jsonMarshal(resource)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if method != http.MethodPatch {
+		return modified, MimeTypeJson, nil
+	}
+
+	patch, err := createMergePatch(original, modified)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := jsonMarshal(patch)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, MimeTypeJsonMergePatch, nil
+}
+
+// createMergePatch returns the JSON Merge Patch (RFC 7386) that turns original into modified.
+// Removed fields are set to null, and nested objects are patched recursively. The values are compared and copied
+// as raw JSON, so the large numbers are not rounded to a float64.
+func createMergePatch(original, modified jsonRawValue) (any, error) {
+	originalObject, err := jsonObject(original)
+	if err != nil {
+		return nil, err
+	}
+	modifiedObject, err := jsonObject(modified)
+	if err != nil {
+		return nil, err
+	}
+	if originalObject == nil || modifiedObject == nil {
+		return modified, nil
+	}
+
+	patch := make(map[string]any)
+	for key := range originalObject {
+		if _, ok := modifiedObject[key]; !ok {
+			patch[key] = nil
+		}
+	}
+
+	for key, value := range modifiedObject {
+		originalField, ok := originalObject[key]
+		if !ok {
+			patch[key] = value
+
+			continue
+		}
+
+		equal, err := jsonEqual(originalField, value)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			if patch[key], err = createMergePatch(originalField, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return patch, nil
+}
+
+// jsonObject decodes the fields of a JSON object, it returns nil if the value is not an object.
+func jsonObject(value jsonRawValue) (map[string]jsonRawValue, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(value), []byte("{")) {
+		return nil, nil
+	}
+
+	var object map[string]jsonRawValue
+	if err := jsonUnmarshalFromReader(bytes.NewReader(value), &object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func jsonEqual(a, b jsonRawValue) (bool, error) {
+	compactA, err := jsonCompact(a)
+	if err != nil {
+		return false, err
+	}
+	compactB, err := jsonCompact(b)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(compactA, compactB), nil
+}
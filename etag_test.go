@@ -0,0 +1,169 @@
+package inpu
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+)
+
+type versionedItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Note     string `json:"note,omitempty"`
+}
+
+func newVersionedItemServer(c *ClientSuite, conflicts int) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	version := 1
+	item := versionedItem{Name: "apple", Quantity: 1, Note: "fresh"}
+	bodies := make([]string, 0)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		eTag := strconv.Quote(strconv.Itoa(version))
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set(HeaderETag, eTag)
+			data, _ := jsonMarshal(item)
+			w.Write(data)
+		default:
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, r.Header.Get(HeaderContentType)+" "+string(body))
+			if conflicts > 0 {
+				conflicts--
+				version++
+				w.WriteHeader(http.StatusPreconditionFailed)
+
+				return
+			}
+
+			c.Require().Equal(eTag, r.Header.Get(HeaderIfMatch))
+			c.Require().NoError(jsonUnmarshalFromReader(bytes.NewReader(body), &item))
+			version++
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})), &bodies
+}
+
+func (c *ClientSuite) Test_UpdateWithETag_Put() {
+	c.T().Parallel()
+	server, bodies := newVersionedItemServer(c, 0)
+	defer server.Close()
+
+	updated, err := UpdateWithETag(New().BasePath(server.URL), "/items/1", func(item *versionedItem) error {
+		item.Quantity++
+
+		return nil
+	}, ETagOptions{})
+
+	c.Require().NoError(err)
+	c.Require().Equal(2, updated.Quantity)
+	c.Require().Equal([]string{`application/json {"name":"apple","quantity":2,"note":"fresh"}`}, *bodies)
+}
+
+func (c *ClientSuite) Test_UpdateWithETag_Retries_On_Precondition_Failed() {
+	c.T().Parallel()
+	server, bodies := newVersionedItemServer(c, 2)
+	defer server.Close()
+
+	updated, err := UpdateWithETag(New().BasePath(server.URL), "/items/1", func(item *versionedItem) error {
+		item.Quantity = 10
+
+		return nil
+	}, ETagOptions{Method: http.MethodPatch})
+
+	c.Require().NoError(err)
+	c.Require().Equal(10, updated.Quantity)
+	c.Require().Len(*bodies, 3)
+	c.Require().Equal(`application/merge-patch+json {"quantity":10}`, (*bodies)[2])
+}
+
+func (c *ClientSuite) Test_UpdateWithETag_Gives_Up() {
+	c.T().Parallel()
+	server, bodies := newVersionedItemServer(c, 10)
+	defer server.Close()
+
+	_, err := UpdateWithETag(New().BasePath(server.URL), "/items/1", func(item *versionedItem) error {
+		return nil
+	}, ETagOptions{MaxRetries: 1})
+
+	c.Require().ErrorIs(err, ErrPreconditionFailed)
+	c.Require().Len(*bodies, 2)
+}
+
+func (c *ClientSuite) Test_UpdateWithETag_Missing_ETag() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"apple"}`))
+	}))
+	defer server.Close()
+
+	_, err := UpdateWithETag(nil, server.URL, func(item *versionedItem) error {
+		return nil
+	}, ETagOptions{})
+
+	c.Require().ErrorIs(err, ErrMissingETag)
+}
+
+func (c *ClientSuite) Test_CreateMergePatch() {
+	c.T().Parallel()
+	original := jsonRawValue(`{"a": "b", "c": {"d": "e", "f": "g"}, "h": "i", "l": [1, 2]}`)
+	modified := jsonRawValue(`{"a":"z","c":{"d":"e"},"j":"k","l":[1,2]}`)
+
+	patch, err := createMergePatch(original, modified)
+	c.Require().NoError(err)
+	data, err := jsonMarshal(patch)
+	c.Require().NoError(err)
+
+	c.Require().JSONEq(`{"a":"z","c":{"f":null},"h":null,"j":"k"}`, string(data))
+}
+
+func (c *ClientSuite) Test_CreateMergePatch_Keeps_Large_Numbers() {
+	c.T().Parallel()
+	original := jsonRawValue(`{"n":{"a":1,"b":9007199254740993}}`)
+	modified := jsonRawValue(`{"n":{"a":9007199254740993,"b":9007199254740993}}`)
+
+	patch, err := createMergePatch(original, modified)
+	c.Require().NoError(err)
+	data, err := jsonMarshal(patch)
+	c.Require().NoError(err)
+
+	c.Require().Equal(`{"n":{"a":9007199254740993}}`, string(data))
+}
+
+func (c *ClientSuite) Test_UpdateWithETag_Patch_Keeps_Large_Numbers() {
+	c.T().Parallel()
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set(HeaderETag, `"1"`)
+			w.Write([]byte(`{"n":{"a":1,"b":9007199254740993}}`))
+
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	type counters struct {
+		N struct {
+			A int64 `json:"a"`
+			B int64 `json:"b"`
+		} `json:"n"`
+	}
+	_, err := UpdateWithETag(nil, server.URL, func(resource *counters) error {
+		resource.N.A = 9007199254740993
+
+		return nil
+	}, ETagOptions{Method: http.MethodPatch})
+
+	c.Require().NoError(err)
+	c.Require().Equal(`{"n":{"a":9007199254740993}}`, <-bodies)
+}
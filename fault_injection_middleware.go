@@ -0,0 +1,396 @@
+package inpu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+type FaultInjectionConfig struct {
+	// Rules are matched in order, the faults of the first rule that matches a request are injected.
+	Rules []FaultRule
+	// Seed makes the injected faults reproducible. Zero means a random seed.
+	Seed uint64
+}
+
+// FaultRule selects the requests to inject faults into. An empty field matches every request.
+type FaultRule struct {
+	// Host matches the host of the request, with or without the port.
+	Host string
+	// Path matches the path of the request with the syntax of path.Match, like /users/*.
+	Path string
+	// Methods match the method of the request.
+	Methods []string
+	// Faults are rolled independently, a request can get the latency and a status for example.
+	Faults []Fault
+}
+
+// Fault is a failure injected by FaultInjectionMiddleware, like LatencyFault or StatusFault.
+type Fault interface {
+	// probability is the chance the fault is injected, between 0 and 1.
+	probability() float64
+	// plan adds the fault to the faults that are injected into the request.
+	plan(p *faultPlan, rng *lockedRand)
+}
+
+// LatencyDistribution returns the latency added by LatencyFault.
+type LatencyDistribution func(rng *rand.Rand) time.Duration
+
+// FixedLatency always adds latency.
+func FixedLatency(latency time.Duration) LatencyDistribution {
+	return func(*rand.Rand) time.Duration {
+		return latency
+	}
+}
+
+// UniformLatency adds a random latency between minLatency and maxLatency.
+func UniformLatency(minLatency, maxLatency time.Duration) LatencyDistribution {
+	return func(rng *rand.Rand) time.Duration {
+		if maxLatency <= minLatency {
+			return minLatency
+		}
+
+		return minLatency + time.Duration(rng.Int64N(int64(maxLatency-minLatency)))
+	}
+}
+
+// NormalLatency adds a random latency with a normal distribution, a negative latency is not added.
+func NormalLatency(mean, stdDev time.Duration) LatencyDistribution {
+	return func(rng *rand.Rand) time.Duration {
+		return max(0, mean+time.Duration(rng.NormFloat64()*float64(stdDev)))
+	}
+}
+
+// LatencyFault delays the request by a latency of the distribution before it is sent.
+func LatencyFault(probability float64, distribution LatencyDistribution) Fault {
+	return &latencyFault{chance: probability, distribution: distribution}
+}
+
+// StatusFault answers with the status and the body instead of sending the request.
+func StatusFault(probability float64, status int, body string) Fault {
+	return &statusFault{chance: probability, status: status, body: body}
+}
+
+// ConnectionResetFault fails the request with a connection reset by peer instead of sending it.
+func ConnectionResetFault(probability float64) Fault {
+	return &connectionResetFault{chance: probability}
+}
+
+// TruncatedBodyFault cuts the response body after n bytes, reading it fails with io.ErrUnexpectedEOF.
+func TruncatedBodyFault(probability float64, n int) Fault {
+	return &truncatedBodyFault{chance: probability, n: n}
+}
+
+// SlowBodyFault waits delay before every read of the response body.
+func SlowBodyFault(probability float64, delay time.Duration) Fault {
+	return &slowBodyFault{chance: probability, delay: delay}
+}
+
+// FaultInjector is the middleware created by FaultInjectionMiddleware, it is disabled until Enable is called.
+type FaultInjector struct {
+	config  FaultInjectionConfig
+	enabled atomic.Bool
+	rng     *lockedRand
+}
+
+type faultInjectionTransport struct {
+	*FaultInjector
+	next http.RoundTripper
+}
+
+// faultPlan are the faults rolled for a request.
+type faultPlan struct {
+	latency  time.Duration
+	response func(req *http.Request) *http.Response
+	err      error
+	bodies   []func(ctx context.Context, body io.ReadCloser) io.ReadCloser
+}
+
+// FaultInjectionMiddleware creates a middleware that injects faults into the matching requests, to test the retry,
+// circuit breaker and timeout configuration against a bad backend. It is the innermost middleware, so the faults
+// look like they come from the server. It is disabled until Enable is called, and can be toggled at runtime.
+// Usage:
+//
+//	faults := FaultInjectionMiddleware(FaultInjectionConfig{
+//		Seed: 42,
+//		Rules: []FaultRule{{
+//			Host: "api.example.com",
+//			Path: "/orders/*",
+//			Faults: []Fault{
+//				LatencyFault(0.5, UniformLatency(100*time.Millisecond, time.Second)),
+//				StatusFault(0.1, http.StatusServiceUnavailable, "overloaded"),
+//			},
+//		}},
+//	})
+//	client := New().Use(RetryMiddleware(3), faults)
+//	faults.Enable()
+func FaultInjectionMiddleware(config FaultInjectionConfig) *FaultInjector {
+	seed := config.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	return &FaultInjector{
+		config: config,
+		rng:    &lockedRand{rng: rand.New(rand.NewPCG(seed, seed))},
+	}
+}
+
+// Enable starts injecting the faults.
+func (f *FaultInjector) Enable() {
+	f.enabled.Store(true)
+}
+
+// Disable stops injecting the faults, the requests are sent as they are.
+func (f *FaultInjector) Disable() {
+	f.enabled.Store(false)
+}
+
+func (f *FaultInjector) Enabled() bool {
+	return f.enabled.Load()
+}
+
+func (f *FaultInjector) ID() string {
+	return "fault-injection-middleware"
+}
+
+func (f *FaultInjector) Priority() int {
+	return 0
+}
+
+func (f *FaultInjector) Apply(next http.RoundTripper) http.RoundTripper {
+	return &faultInjectionTransport{FaultInjector: f, next: next}
+}
+
+func (t *faultInjectionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.Enabled() {
+		return t.next.RoundTrip(req)
+	}
+
+	rule := t.match(req)
+	if rule == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	plan := &faultPlan{}
+	for _, fault := range rule.Faults {
+		if t.rng.float64() < fault.probability() {
+			fault.plan(plan, t.rng)
+		}
+	}
+
+	ctx := req.Context()
+	logger := ExtractLoggerFromContext(ctx)
+	if plan.latency > 0 {
+		logger.Debug(ctx, "[FAULT] Delaying %s %s by %v", req.Method, req.URL.Redacted(), plan.latency)
+		select {
+		case <-ExtractClockFromContext(ctx).After(plan.latency):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if plan.response != nil {
+		resp := plan.response(req)
+		logger.Debug(ctx, "[FAULT] Answering %s %s with %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+
+		return resp, nil
+	}
+	if plan.err != nil {
+		logger.Debug(ctx, "[FAULT] Failing %s %s with %v", req.Method, req.URL.Redacted(), plan.err)
+
+		return nil, plan.err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+	for _, wrap := range plan.bodies {
+		resp.Body = wrap(ctx, resp.Body)
+	}
+
+	return resp, nil
+}
+
+// match returns the first rule that matches the request.
+func (f *FaultInjector) match(req *http.Request) *FaultRule {
+	for i := range f.config.Rules {
+		rule := &f.config.Rules[i]
+		if rule.Host != "" && rule.Host != req.URL.Host && rule.Host != req.URL.Hostname() {
+			continue
+		}
+		if rule.Path != "" {
+			if matched, err := path.Match(rule.Path, req.URL.Path); err != nil || !matched {
+				continue
+			}
+		}
+		if len(rule.Methods) > 0 && !slices.Contains(rule.Methods, req.Method) {
+			continue
+		}
+
+		return rule
+	}
+
+	return nil
+}
+
+type latencyFault struct {
+	chance       float64
+	distribution LatencyDistribution
+}
+
+func (f *latencyFault) probability() float64 {
+	return f.chance
+}
+
+func (f *latencyFault) plan(p *faultPlan, rng *lockedRand) {
+	p.latency += rng.latency(f.distribution)
+}
+
+type statusFault struct {
+	chance float64
+	status int
+	body   string
+}
+
+func (f *statusFault) probability() float64 {
+	return f.chance
+}
+
+func (f *statusFault) plan(p *faultPlan, _ *lockedRand) {
+	if p.response != nil || p.err != nil {
+		return
+	}
+
+	p.response = func(req *http.Request) *http.Response {
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", f.status, http.StatusText(f.status)),
+			StatusCode:    f.status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{HeaderContentType: []string{MimeTypeText}},
+			Body:          io.NopCloser(strings.NewReader(f.body)),
+			ContentLength: int64(len(f.body)),
+			Request:       req,
+		}
+	}
+}
+
+type connectionResetFault struct {
+	chance float64
+}
+
+func (f *connectionResetFault) probability() float64 {
+	return f.chance
+}
+
+func (f *connectionResetFault) plan(p *faultPlan, _ *lockedRand) {
+	if p.response != nil || p.err != nil {
+		return
+	}
+
+	p.err = &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+}
+
+type truncatedBodyFault struct {
+	chance float64
+	n      int
+}
+
+func (f *truncatedBodyFault) probability() float64 {
+	return f.chance
+}
+
+func (f *truncatedBodyFault) plan(p *faultPlan, _ *lockedRand) {
+	p.bodies = append(p.bodies, func(_ context.Context, body io.ReadCloser) io.ReadCloser {
+		return &truncatedBody{ReadCloser: body, remaining: f.n}
+	})
+}
+
+type slowBodyFault struct {
+	chance float64
+	delay  time.Duration
+}
+
+func (f *slowBodyFault) probability() float64 {
+	return f.chance
+}
+
+func (f *slowBodyFault) plan(p *faultPlan, _ *lockedRand) {
+	p.bodies = append(p.bodies, func(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+		return &slowBody{ReadCloser: body, ctx: ctx, delay: f.delay}
+	})
+}
+
+// truncatedBody fails with io.ErrUnexpectedEOF after remaining bytes, like a connection closed mid-body.
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	n, err := b.ReadCloser.Read(p[:min(len(p), b.remaining)])
+	b.remaining -= n
+	if err == io.EOF {
+		return n, err
+	}
+	if b.remaining <= 0 && err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
+
+// slowBody waits before every read, like a slow connection.
+type slowBody struct {
+	io.ReadCloser
+	ctx   context.Context
+	delay time.Duration
+}
+
+func (b *slowBody) Read(p []byte) (int, error) {
+	select {
+	case <-ExtractClockFromContext(b.ctx).After(b.delay):
+	case <-b.ctx.Done():
+		return 0, b.ctx.Err()
+	}
+
+	return b.ReadCloser.Read(p)
+}
+
+// lockedRand is a seeded random generator that can be used concurrently.
+type lockedRand struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func (r *lockedRand) float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rng.Float64()
+}
+
+func (r *lockedRand) latency(distribution LatencyDistribution) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return distribution(r.rng)
+}
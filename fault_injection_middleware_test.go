@@ -0,0 +1,179 @@
+package inpu
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/denizgursoy/inpu/inputest"
+)
+
+func newFaultServer(calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+}
+
+func (c *ClientSuite) Test_FaultInjection_Disabled_By_Default() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newFaultServer(&calls)
+	defer server.Close()
+
+	faults := FaultInjectionMiddleware(FaultInjectionConfig{
+		Rules: []FaultRule{{Faults: []Fault{StatusFault(1, http.StatusServiceUnavailable, "down")}}},
+	})
+	client := New().BasePath(server.URL).Use(faults)
+
+	c.Require().NoError(client.Get("/").OnOk(ThenDoNothing).On(StatusAny, ThenReturnDefaultError).Send())
+
+	faults.Enable()
+	var body string
+	c.Require().NoError(client.Get("/").
+		On(StatusIs(http.StatusServiceUnavailable), func(r *http.Response) error {
+			data, err := io.ReadAll(r.Body)
+			body = string(data)
+
+			return err
+		}).
+		Send())
+	c.Require().Equal("down", body)
+
+	faults.Disable()
+	c.Require().NoError(client.Get("/").OnOk(ThenDoNothing).On(StatusAny, ThenReturnDefaultError).Send())
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_FaultInjection_Matches_Rules() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newFaultServer(&calls)
+	defer server.Close()
+
+	faults := FaultInjectionMiddleware(FaultInjectionConfig{
+		Rules: []FaultRule{{
+			Host:    "127.0.0.1",
+			Path:    "/orders/*",
+			Methods: []string{http.MethodPost},
+			Faults:  []Fault{ConnectionResetFault(1)},
+		}},
+	})
+	faults.Enable()
+	client := New().BasePath(server.URL).Use(faults)
+
+	err := client.Post("/orders/1", nil).Send()
+	c.Require().ErrorIs(err, syscall.ECONNRESET)
+	c.Require().Equal(ErrorClassTransient, ClassifyError(err))
+
+	c.Require().NoError(client.Get("/orders/1").Send())
+	c.Require().NoError(client.Post("/users/1", nil).Send())
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_FaultInjection_Probability_Is_Seeded() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newFaultServer(&calls)
+	defer server.Close()
+
+	statuses := func() []int {
+		faults := FaultInjectionMiddleware(FaultInjectionConfig{
+			Seed:  7,
+			Rules: []FaultRule{{Faults: []Fault{StatusFault(0.5, http.StatusInternalServerError, "")}}},
+		})
+		faults.Enable()
+		client := New().BasePath(server.URL).Use(faults)
+
+		result := make([]int, 0, 20)
+		for range 20 {
+			c.Require().NoError(client.Get("/").On(StatusAny, func(r *http.Response) error {
+				result = append(result, r.StatusCode)
+
+				return nil
+			}).Send())
+		}
+
+		return result
+	}
+
+	first := statuses()
+	c.Require().Equal(first, statuses())
+	c.Require().Contains(first, http.StatusOK)
+	c.Require().Contains(first, http.StatusInternalServerError)
+}
+
+func (c *ClientSuite) Test_FaultInjection_Latency() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newFaultServer(&calls)
+	defer server.Close()
+
+	clock := inputest.NewFakeClock(time.Now())
+	faults := FaultInjectionMiddleware(FaultInjectionConfig{
+		Rules: []FaultRule{{Faults: []Fault{LatencyFault(1, FixedLatency(time.Minute))}}},
+	})
+	faults.Enable()
+	client := New().BasePath(server.URL).Clock(clock).Use(faults)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Get("/").Send()
+	}()
+
+	clock.BlockUntil(1)
+	c.Require().Zero(calls.Load())
+	clock.Advance(time.Minute)
+	c.Require().NoError(<-done)
+	c.Require().EqualValues(1, calls.Load())
+}
+
+func (c *ClientSuite) Test_FaultInjection_Truncated_Body() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newFaultServer(&calls)
+	defer server.Close()
+
+	faults := FaultInjectionMiddleware(FaultInjectionConfig{
+		Rules: []FaultRule{{Faults: []Fault{TruncatedBodyFault(1, 4)}}},
+	})
+	faults.Enable()
+	client := New().BasePath(server.URL).Use(faults)
+
+	var body []byte
+	err := client.Get("/").OnOk(func(r *http.Response) error {
+		var err error
+		body, err = io.ReadAll(r.Body)
+
+		return err
+	}).Send()
+	c.Require().ErrorIs(err, io.ErrUnexpectedEOF)
+	c.Require().Equal("0123", string(body))
+}
+
+func (c *ClientSuite) Test_FaultInjection_Slow_Body() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := newFaultServer(&calls)
+	defer server.Close()
+
+	faults := FaultInjectionMiddleware(FaultInjectionConfig{
+		Rules: []FaultRule{{Faults: []Fault{SlowBodyFault(1, 20*time.Millisecond)}}},
+	})
+	faults.Enable()
+	client := New().BasePath(server.URL).Use(faults)
+
+	start := time.Now()
+	var body []byte
+	c.Require().NoError(client.Get("/").OnOk(func(r *http.Response) error {
+		var err error
+		body, err = io.ReadAll(r.Body)
+
+		return err
+	}).Send())
+	c.Require().Equal("0123456789", string(body))
+	c.Require().GreaterOrEqual(time.Since(start), 20*time.Millisecond)
+}
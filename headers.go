@@ -16,8 +16,22 @@ const (
 	HeaderAPISecret     = "X-Api-Secret"
 	HeaderAPIToken      = "X-Api-Token"
 
-	// Retry
-	HeaderRetryAfter = "Retry-After"
+	// Retry and rate limiting
+	HeaderRetryAfter          = "Retry-After"
+	HeaderRateLimitLimit      = "RateLimit-Limit"
+	HeaderRateLimitRemaining  = "RateLimit-Remaining"
+	HeaderRateLimitReset      = "RateLimit-Reset"
+	HeaderXRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderXRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderXRateLimitReset     = "X-RateLimit-Reset"
+
+	// Pagination
+	HeaderLink        = "Link"
+	HeaderXTotalCount = "X-Total-Count"
+
+	// Long-running operations
+	HeaderLocation          = "Location"
+	HeaderOperationLocation = "Operation-Location"
 
 	// Client identification
 	HeaderUserAgent = "User-Agent"
@@ -31,6 +45,12 @@ const (
 	HeaderIfMatch           = "If-Match"
 	HeaderIfUnmodifiedSince = "If-Unmodified-Since"
 	HeaderIfRange           = "If-Range"
+	HeaderETag              = "ETag"
+	HeaderLastModified      = "Last-Modified"
+	HeaderExpires           = "Expires"
+	HeaderAge               = "Age"
+	HeaderVary              = "Vary"
+	HeaderDate              = "Date"
 
 	// Request control
 	HeaderExpect     = "Expect"
@@ -45,6 +65,7 @@ const (
 
 	// Request tracking and tracing
 	HeaderXRequestID     = "X-Request-ID"
+	HeaderIdempotencyKey = "Idempotency-Key"
 	HeaderXCorrelationID = "X-Correlation-ID"
 	HeaderXTraceID       = "X-Trace-ID"
 	HeaderXSpanID        = "X-Span-ID"
@@ -55,6 +76,12 @@ const (
 	HeaderXForwardedFor  = "X-Forwarded-For"
 	HeaderXRealIP        = "X-Real-IP"
 
+	// Request signing
+	HeaderXSignature          = "X-Signature"
+	HeaderXSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderXSignatureNonce     = "X-Signature-Nonce"
+	HeaderXSignatureKeyID     = "X-Signature-Key-Id"
+
 	// Security headers (client-side)
 	HeaderXCSRFToken     = "X-Csrf-Token"
 	HeaderXRequestedWith = "X-Requested-With"
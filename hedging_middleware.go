@@ -0,0 +1,285 @@
+package inpu
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	ContextKeyHedgeAttempt = "inpu_hedge_attempt"
+
+	defaultHedgeLatencyWindow  = 100
+	minimumHedgeLatencySamples = 10
+)
+
+type HedgingConfig struct {
+	// Delay is the time to wait for an attempt before sending the next one. With Percentile, it is used until
+	// enough latencies are recorded.
+	Delay time.Duration
+	// MaxHedges is the number of attempts sent in addition to the first one. Default is 1.
+	MaxHedges int
+	// Percentile makes the delay the percentile of the recent latencies, between 0 and 1 like 0.95.
+	// Zero means the delay is always Delay.
+	Percentile float64
+	// LatencyWindow is the number of recent latencies the percentile is calculated from. Default is 100.
+	LatencyWindow int
+	// HedgeUnsafeMethods enables hedging for the methods that are not safe, like POST. Only enable it when the
+	// server handles duplicate requests, with an idempotency key for example.
+	HedgeUnsafeMethods bool
+}
+
+type hedgingMiddleware struct {
+	config    HedgingConfig
+	mu        sync.Mutex
+	latencies []time.Duration
+	oldest    int
+}
+
+type hedgingTransport struct {
+	*hedgingMiddleware
+	next http.RoundTripper
+}
+
+type hedgeResult struct {
+	hedge   int
+	resp    *http.Response
+	err     error
+	latency time.Duration
+}
+
+// HedgingMiddleware creates a hedging middleware that sends up to maxHedges duplicate attempts of a GET, HEAD or
+// OPTIONS request when the previous attempt has not answered within delay. See HedgingMiddlewareWithConfig.
+func HedgingMiddleware(delay time.Duration, maxHedges int) Middleware {
+	return HedgingMiddlewareWithConfig(HedgingConfig{
+		Delay:     delay,
+		MaxHedges: maxHedges,
+	})
+}
+
+// HedgingMiddlewareWithConfig creates a hedging middleware that reduces the tail latency against replicated
+// backends: when an attempt has not answered within the delay, a duplicate attempt is sent, and the first successful
+// response is returned. The other attempts are cancelled and their bodies drained. Every attempt has its hedge
+// number in the context, see ExtractHedgeAttemptFromContext.
+// Usage:
+//
+//	client := New().Use(HedgingMiddlewareWithConfig(HedgingConfig{
+//		Delay:      100 * time.Millisecond,
+//		MaxHedges:  2,
+//		Percentile: 0.95,
+//	}))
+func HedgingMiddlewareWithConfig(config HedgingConfig) Middleware {
+	if config.MaxHedges <= 0 {
+		config.MaxHedges = 1
+	}
+	if config.LatencyWindow <= 0 {
+		config.LatencyWindow = defaultHedgeLatencyWindow
+	}
+
+	return &hedgingMiddleware{
+		config:    config,
+		latencies: make([]time.Duration, 0, config.LatencyWindow),
+	}
+}
+
+func (t *hedgingMiddleware) ID() string {
+	return "hedging-middleware"
+}
+
+func (t *hedgingMiddleware) Priority() int {
+	return 24
+}
+
+func (t *hedgingMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
+	return &hedgingTransport{hedgingMiddleware: t, next: next}
+}
+
+func (t *hedgingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.canHedge(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	// the channel is never blocked, the attempts that lose can always send their result
+	results := make(chan hedgeResult, t.config.MaxHedges+1)
+	cancels := make([]context.CancelFunc, 0, t.config.MaxHedges+1)
+	pending := 0
+	send := func() {
+		hedge := len(cancels)
+		ctx, cancel := context.WithCancel(context.WithValue(req.Context(), ContextKeyHedgeAttempt, hedge))
+		cancels = append(cancels, cancel)
+
+		attempt, err := t.cloneRequest(ctx, req, hedge)
+		if err != nil {
+			cancel()
+
+			return
+		}
+
+		pending++
+		go func() {
+			start := time.Now()
+			resp, err := t.next.RoundTrip(attempt)
+			results <- hedgeResult{hedge: hedge, resp: resp, err: err, latency: time.Since(start)}
+		}()
+	}
+
+	send()
+	timer := time.NewTimer(t.delay())
+	defer timer.Stop()
+
+	for {
+		select {
+		case result := <-results:
+			pending--
+			if result.err == nil && !checkRetryBasedOnStatusCode(result.resp) {
+				t.record(result.latency)
+
+				return t.finish(result, cancels, results, pending), nil
+			}
+
+			// a failure is only returned when there is no other attempt to wait for
+			if pending == 0 {
+				return t.finish(result, cancels, results, pending), result.err
+			}
+			t.discard(result, cancels)
+		case <-timer.C:
+			if len(cancels) <= t.config.MaxHedges {
+				send()
+				timer.Reset(t.delay())
+			}
+		case <-req.Context().Done():
+			for _, cancel := range cancels {
+				cancel()
+			}
+			go t.drain(results, cancels, pending)
+
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// finish cancels the attempts that lost and returns the response of the winner, whose context is cancelled when
+// its body is closed.
+func (t *hedgingTransport) finish(winner hedgeResult, cancels []context.CancelFunc, results chan hedgeResult,
+	pending int,
+) *http.Response {
+	for hedge, cancel := range cancels {
+		if hedge != winner.hedge {
+			cancel()
+		}
+	}
+	go t.drain(results, cancels, pending)
+
+	if winner.resp == nil {
+		cancels[winner.hedge]()
+
+		return nil
+	}
+
+	if winner.resp.Body == nil {
+		cancels[winner.hedge]()
+	} else {
+		winner.resp.Body = &cancelOnCloseBody{ReadCloser: winner.resp.Body, cancel: cancels[winner.hedge]}
+	}
+
+	return winner.resp
+}
+
+// drain waits for the attempts still in flight and closes their responses.
+func (t *hedgingTransport) drain(results chan hedgeResult, cancels []context.CancelFunc, pending int) {
+	for range pending {
+		t.discard(<-results, cancels)
+	}
+}
+
+func (t *hedgingTransport) discard(result hedgeResult, cancels []context.CancelFunc) {
+	DrainBodyAndClose(result.resp)
+	cancels[result.hedge]()
+}
+
+func (t *hedgingMiddleware) canHedge(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	return t.config.HedgeUnsafeMethods || req.Method == http.MethodGet || req.Method == http.MethodHead ||
+		req.Method == http.MethodOptions
+}
+
+func (t *hedgingMiddleware) cloneRequest(ctx context.Context, req *http.Request, hedge int) (*http.Request, error) {
+	attempt := req.Clone(ctx)
+	if hedge > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attempt.Body = body
+	}
+
+	return attempt, nil
+}
+
+// delay returns the percentile of the recent latencies, or Delay until enough latencies are recorded.
+func (t *hedgingMiddleware) delay() time.Duration {
+	if t.config.Percentile <= 0 {
+		return t.config.Delay
+	}
+
+	t.mu.Lock()
+	if len(t.latencies) < minimumHedgeLatencySamples {
+		t.mu.Unlock()
+
+		return t.config.Delay
+	}
+	latencies := slices.Clone(t.latencies)
+	t.mu.Unlock()
+
+	slices.Sort(latencies)
+	index := min(int(float64(len(latencies))*t.config.Percentile), len(latencies)-1)
+
+	return latencies[index]
+}
+
+func (t *hedgingMiddleware) record(latency time.Duration) {
+	if t.config.Percentile <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.latencies) < t.config.LatencyWindow {
+		t.latencies = append(t.latencies, latency)
+
+		return
+	}
+	t.latencies[t.oldest] = latency
+	t.oldest = (t.oldest + 1) % t.config.LatencyWindow
+}
+
+// cancelOnCloseBody cancels the context of the attempt when the body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
+
+// ExtractHedgeAttemptFromContext returns the hedge attempt of the request, 0 for the first attempt
+// and for the requests that are not hedged.
+func ExtractHedgeAttemptFromContext(ctx context.Context) int {
+	attempt, ok := ctx.Value(ContextKeyHedgeAttempt).(int)
+	if !ok {
+		return 0
+	}
+
+	return attempt
+}
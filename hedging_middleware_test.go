@@ -0,0 +1,125 @@
+package inpu
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+)
+
+// newSlowFirstServer answers the first request after slow, or when it is cancelled, and the others right away.
+func newSlowFirstServer(calls *atomic.Int32, cancelled *atomic.Bool, slow time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := calls.Add(1)
+		if call == 1 {
+			select {
+			case <-r.Context().Done():
+				cancelled.Store(true)
+
+				return
+			case <-time.After(slow):
+			}
+		}
+		w.Header().Set(HeaderContentType, MimeTypeJson)
+		_, _ = fmt.Fprintf(w, `{"call":%d,"hedge":%q}`, call, r.Header.Get("X-Hedge"))
+	}))
+}
+
+// hedgeHeaderMiddleware sends the hedge attempt of the request to the server.
+func hedgeHeaderMiddleware() Middleware {
+	return RequestModifierMiddleware(func(req *http.Request) (*http.Request, error) {
+		req.Header.Set("X-Hedge", fmt.Sprint(ExtractHedgeAttemptFromContext(req.Context())))
+
+		return req, nil
+	}, "hedge-header", 0)
+}
+
+func (c *ClientSuite) Test_Hedging_Returns_First_Response() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	var cancelled atomic.Bool
+	server := newSlowFirstServer(&calls, &cancelled, time.Second)
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(HedgingMiddleware(20*time.Millisecond, 1), hedgeHeaderMiddleware())
+
+	start := time.Now()
+	result := map[string]any{}
+	c.Require().NoError(client.Get("/").OnOk(ThenUnmarshalJsonTo(&result)).Send())
+
+	c.Require().Less(time.Since(start), 500*time.Millisecond)
+	c.Require().EqualValues(2, result["call"])
+	c.Require().Equal("1", result["hedge"])
+
+	// the attempt that lost is cancelled
+	c.Require().Eventually(cancelled.Load, time.Second, 5*time.Millisecond)
+}
+
+func (c *ClientSuite) Test_Hedging_Does_Not_Hedge_Fast_Responses() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	var cancelled atomic.Bool
+	server := newSlowFirstServer(&calls, &cancelled, 0)
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(HedgingMiddleware(200*time.Millisecond, 2))
+
+	c.Require().NoError(client.Get("/").Send())
+	c.Require().EqualValues(1, calls.Load())
+}
+
+func (c *ClientSuite) Test_Hedging_Only_Safe_Methods() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	var cancelled atomic.Bool
+	server := newSlowFirstServer(&calls, &cancelled, 100*time.Millisecond)
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(HedgingMiddleware(10*time.Millisecond, 1))
+	c.Require().NoError(client.Post("/", BodyJson(map[string]string{"a": "b"})).Send())
+	c.Require().EqualValues(1, calls.Load())
+
+	calls.Store(0)
+	unsafe := New().BasePath(server.URL).Use(HedgingMiddlewareWithConfig(HedgingConfig{
+		Delay:              10 * time.Millisecond,
+		HedgeUnsafeMethods: true,
+	}))
+	c.Require().NoError(unsafe.Post("/", BodyJson(map[string]string{"a": "b"})).Send())
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_Hedging_Caller_Cancels() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	var cancelled atomic.Bool
+	server := newSlowFirstServer(&calls, &cancelled, time.Second)
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(HedgingMiddleware(time.Second, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c.Require().ErrorIs(client.GetCtx(ctx, "/").Send(), context.DeadlineExceeded)
+	c.Require().Eventually(cancelled.Load, time.Second, 5*time.Millisecond)
+}
+
+func (c *ClientSuite) Test_Hedging_Percentile_Delay() {
+	c.T().Parallel()
+	middleware := HedgingMiddlewareWithConfig(HedgingConfig{
+		Delay:         time.Second,
+		Percentile:    0.9,
+		LatencyWindow: 20,
+	}).(*hedgingMiddleware)
+
+	// Delay is used until enough latencies are recorded
+	c.Require().Equal(time.Second, middleware.delay())
+
+	for i := range 30 {
+		middleware.record(time.Duration(i+1) * time.Millisecond)
+	}
+
+	// the window keeps the last 20 latencies, 11ms to 30ms
+	c.Require().Equal(29*time.Millisecond, middleware.delay())
+}
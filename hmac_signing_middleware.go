@@ -0,0 +1,291 @@
+package inpu
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrMissingSigningKey = errors.New("hmac signing key is not set")
+
+// HMACAlgorithm creates the hash the signature and the body hash are calculated with.
+type HMACAlgorithm func() hash.Hash
+
+var (
+	HMACSHA256 HMACAlgorithm = sha256.New
+	HMACSHA512 HMACAlgorithm = sha512.New
+)
+
+// HMACKey is a shared secret and the ID the server finds it with.
+type HMACKey struct {
+	ID     string
+	Secret []byte
+}
+
+// HMACKeyProvider returns the key to sign a request with. Returning a new key rotates it, the server can accept the
+// previous key by its ID for a while.
+type HMACKeyProvider func(ctx context.Context) (HMACKey, error)
+
+// HMACCanonicalRequest are the parts of a request that are signed.
+type HMACCanonicalRequest struct {
+	Method string
+	Host   string
+	// Path is the escaped path of the URL.
+	Path string
+	// Query is the escaped query sorted by name and value.
+	Query     string
+	Timestamp string
+	// Nonce is empty when NonceHeader is not set.
+	Nonce string
+	KeyID string
+	// BodyHash is the hex hash of the body with the algorithm of the signature, the hash of an empty body if there
+	// is no body.
+	BodyHash string
+}
+
+// HMACCanonicalizer returns the string that is signed.
+type HMACCanonicalizer func(request HMACCanonicalRequest) (string, error)
+
+// DefaultHMACCanonicalizer joins the method, the path, the query, the timestamp, the nonce and the body hash with
+// new lines.
+func DefaultHMACCanonicalizer(request HMACCanonicalRequest) (string, error) {
+	return strings.Join([]string{
+		request.Method, request.Path, request.Query, request.Timestamp, request.Nonce, request.BodyHash,
+	}, "\n"), nil
+}
+
+// HMACTemplate creates a canonicalizer from a text/template executed with the HMACCanonicalRequest, like
+// "{{.Timestamp}}.{{.Method}}.{{.Path}}.{{.BodyHash}}".
+func HMACTemplate(text string) (HMACCanonicalizer, error) {
+	tmpl, err := template.New("hmac").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(request HMACCanonicalRequest) (string, error) {
+		var canonical strings.Builder
+		if err := tmpl.Execute(&canonical, request); err != nil {
+			return "", err
+		}
+
+		return canonical.String(), nil
+	}, nil
+}
+
+type HMACSigningConfig struct {
+	// Key signs every request. KeyProvider is used instead when it is set.
+	Key HMACKey
+	// KeyProvider returns the key of every request, to rotate the keys without creating a new client.
+	KeyProvider HMACKeyProvider
+	// Algorithm is the hash of the signature and of the body. Default is HMACSHA256.
+	Algorithm HMACAlgorithm
+	// Canonicalizer builds the signed string. Default is DefaultHMACCanonicalizer, see HMACTemplate.
+	Canonicalizer HMACCanonicalizer
+	// SignatureHeader is the header of the signature. Default is X-Signature.
+	SignatureHeader string
+	// SignaturePrefix is written before the signature, like "sha256=".
+	SignaturePrefix string
+	// Encode encodes the signature. Default is hex.EncodeToString, base64.StdEncoding.EncodeToString is common too.
+	Encode func(signature []byte) string
+	// TimestampHeader is the header of the timestamp. Default is X-Signature-Timestamp.
+	TimestampHeader string
+	// FormatTimestamp formats the time of the signature. Default is the Unix time in seconds.
+	FormatTimestamp func(now time.Time) string
+	// NonceHeader sends a random nonce in the header and signs it, so the server can reject a replayed request.
+	// Empty means no nonce.
+	NonceHeader string
+	// KeyIDHeader is the header of the key ID, it is not sent when the key has no ID. Default is X-Signature-Key-Id.
+	KeyIDHeader string
+}
+
+type hmacSigningMiddleware struct {
+	config HMACSigningConfig
+}
+
+type hmacSigningTransport struct {
+	*hmacSigningMiddleware
+	next http.RoundTripper
+}
+
+// HMACSigningMiddleware creates a middleware that signs every request with an HMAC of a canonical string of the
+// request, for the webhook-style APIs that authenticate the requests with a shared secret. The body is hashed from
+// GetBody, so the body the transport sends is not consumed. It runs after RetryMiddleware, so every attempt gets a
+// new timestamp and nonce.
+// Usage:
+//
+//	canonicalizer, _ := HMACTemplate("{{.Timestamp}}\n{{.Method}}\n{{.Path}}\n{{.BodyHash}}")
+//	client := New().Use(HMACSigningMiddleware(HMACSigningConfig{
+//		Key:           HMACKey{ID: "2024-01", Secret: []byte(secret)},
+//		Algorithm:     HMACSHA512,
+//		Canonicalizer: canonicalizer,
+//		NonceHeader:   HeaderXSignatureNonce,
+//	}))
+func HMACSigningMiddleware(config HMACSigningConfig) Middleware {
+	if config.KeyProvider == nil {
+		key := config.Key
+		config.KeyProvider = func(context.Context) (HMACKey, error) {
+			return key, nil
+		}
+	}
+	if config.Algorithm == nil {
+		config.Algorithm = HMACSHA256
+	}
+	if config.Canonicalizer == nil {
+		config.Canonicalizer = DefaultHMACCanonicalizer
+	}
+	if config.SignatureHeader == "" {
+		config.SignatureHeader = HeaderXSignature
+	}
+	if config.Encode == nil {
+		config.Encode = hex.EncodeToString
+	}
+	if config.TimestampHeader == "" {
+		config.TimestampHeader = HeaderXSignatureTimestamp
+	}
+	if config.FormatTimestamp == nil {
+		config.FormatTimestamp = func(now time.Time) string {
+			return strconv.FormatInt(now.Unix(), 10)
+		}
+	}
+	if config.KeyIDHeader == "" {
+		config.KeyIDHeader = HeaderXSignatureKeyID
+	}
+
+	return &hmacSigningMiddleware{config: config}
+}
+
+func (t *hmacSigningMiddleware) ID() string {
+	return "hmac-signing-middleware"
+}
+
+// Priority 15 is inside the retry and hedging middlewares, so every attempt has its own timestamp and nonce. The
+// headers of the inner middlewares, like traceparent of OTel, are not part of the canonical string, they do not
+// change the signature.
+func (t *hmacSigningMiddleware) Priority() int {
+	return 15
+}
+
+func (t *hmacSigningMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
+	return &hmacSigningTransport{hmacSigningMiddleware: t, next: next}
+}
+
+func (t *hmacSigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	key, err := t.config.KeyProvider(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the hmac signing key: %w", err)
+	}
+	if len(key.Secret) == 0 {
+		return nil, ErrMissingSigningKey
+	}
+
+	// the signature headers and a body read into memory are set on a copy, the next attempt starts unsigned
+	signed := req.Clone(ctx)
+	bodyHash, err := t.hashBody(signed)
+	if err != nil {
+		return nil, fmt.Errorf("could not hash the request body: %w", err)
+	}
+
+	canonicalRequest := HMACCanonicalRequest{
+		Method:    signed.Method,
+		Host:      signed.Host,
+		Path:      signed.URL.EscapedPath(),
+		Query:     sortedQuery(signed.URL.Query()),
+		Timestamp: t.config.FormatTimestamp(ExtractClockFromContext(ctx).Now()),
+		KeyID:     key.ID,
+		BodyHash:  bodyHash,
+	}
+	if canonicalRequest.Host == "" {
+		canonicalRequest.Host = signed.URL.Host
+	}
+	if t.config.NonceHeader != "" {
+		canonicalRequest.Nonce = uuid.New().String()
+		signed.Header.Set(t.config.NonceHeader, canonicalRequest.Nonce)
+	}
+
+	canonical, err := t.config.Canonicalizer(canonicalRequest)
+	if err != nil {
+		return nil, fmt.Errorf("could not build the signed string: %w", err)
+	}
+
+	mac := hmac.New(t.config.Algorithm, key.Secret)
+	mac.Write([]byte(canonical))
+	signed.Header.Set(t.config.SignatureHeader, t.config.SignaturePrefix+t.config.Encode(mac.Sum(nil)))
+	signed.Header.Set(t.config.TimestampHeader, canonicalRequest.Timestamp)
+	if key.ID != "" {
+		signed.Header.Set(t.config.KeyIDHeader, key.ID)
+	}
+
+	return t.next.RoundTrip(signed)
+}
+
+// hashBody hashes the body read from GetBody. A body without GetBody is read into memory and replaced.
+func (t *hmacSigningMiddleware) hashBody(req *http.Request) (string, error) {
+	digest := t.config.Algorithm()
+	if req.Body == nil || req.Body == http.NoBody {
+		return hex.EncodeToString(digest.Sum(nil)), nil
+	}
+
+	if req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		digest.Write(body)
+
+		return hex.EncodeToString(digest.Sum(nil)), nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	if _, err := io.Copy(digest, body); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// sortedQuery escapes the query sorted by name and value.
+func sortedQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	parameters := make([]string, 0, len(query))
+	for _, name := range names {
+		values := slices.Clone(query[name])
+		slices.Sort(values)
+		for _, value := range values {
+			parameters = append(parameters, url.QueryEscape(name)+"="+url.QueryEscape(value))
+		}
+	}
+
+	return strings.Join(parameters, "&")
+}
@@ -0,0 +1,188 @@
+package inpu
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/denizgursoy/inpu/inputest"
+)
+
+type signedRequest struct {
+	header http.Header
+	body   string
+}
+
+func newHMACServer(requests *[]signedRequest, mu *sync.Mutex) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		*requests = append(*requests, signedRequest{header: r.Header.Clone(), body: string(body)})
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func hmacHex(algorithm func() hash.Hash, secret, data string) []byte {
+	mac := hmac.New(algorithm, []byte(secret))
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func (c *ClientSuite) Test_HMACSigning_Default() {
+	c.T().Parallel()
+	var mu sync.Mutex
+	requests := make([]signedRequest, 0)
+	server := newHMACServer(&requests, &mu)
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := New().
+		BasePath(server.URL).
+		Clock(inputest.NewFakeClock(now)).
+		Use(HMACSigningMiddleware(HMACSigningConfig{Key: HMACKey{ID: "key-1", Secret: []byte("secret")}}))
+
+	c.Require().NoError(client.Post("/orders", BodyString(`{"id":1}`)).
+		QueryString("b", "2").
+		QueryString("a", "1").
+		Send())
+
+	c.Require().Len(requests, 1)
+	bodyHash := sha256.Sum256([]byte(`{"id":1}`))
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	canonical := "POST\n/orders\na=1&b=2\n" + timestamp + "\n\n" + hex.EncodeToString(bodyHash[:])
+
+	c.Require().Equal(`{"id":1}`, requests[0].body)
+	c.Require().Equal(timestamp, requests[0].header.Get(HeaderXSignatureTimestamp))
+	c.Require().Equal("key-1", requests[0].header.Get(HeaderXSignatureKeyID))
+	c.Require().Equal(hex.EncodeToString(hmacHex(sha256.New, "secret", canonical)),
+		requests[0].header.Get(HeaderXSignature))
+}
+
+func (c *ClientSuite) Test_HMACSigning_Template_SHA512_And_Nonce() {
+	c.T().Parallel()
+	var mu sync.Mutex
+	requests := make([]signedRequest, 0)
+	server := newHMACServer(&requests, &mu)
+	defer server.Close()
+
+	canonicalizer, err := HMACTemplate("{{.Timestamp}}.{{.Nonce}}.{{.Method}}.{{.Path}}.{{.BodyHash}}")
+	c.Require().NoError(err)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := New().
+		BasePath(server.URL).
+		Clock(inputest.NewFakeClock(now)).
+		Use(HMACSigningMiddleware(HMACSigningConfig{
+			Key:             HMACKey{Secret: []byte("secret")},
+			Algorithm:       HMACSHA512,
+			Canonicalizer:   canonicalizer,
+			SignaturePrefix: "sha512=",
+			Encode:          base64.StdEncoding.EncodeToString,
+			FormatTimestamp: func(now time.Time) string {
+				return now.Format(time.RFC3339)
+			},
+			NonceHeader: HeaderXSignatureNonce,
+		}))
+
+	c.Require().NoError(client.Get("/events").Send())
+	c.Require().NoError(client.Get("/events").Send())
+
+	c.Require().Len(requests, 2)
+	nonce := requests[0].header.Get(HeaderXSignatureNonce)
+	c.Require().NotEmpty(nonce)
+	c.Require().NotEqual(nonce, requests[1].header.Get(HeaderXSignatureNonce))
+	c.Require().Empty(requests[0].header.Get(HeaderXSignatureKeyID))
+
+	bodyHash := sha512.Sum512(nil)
+	canonical := "2024-01-01T00:00:00Z." + nonce + ".GET./events." + hex.EncodeToString(bodyHash[:])
+	c.Require().Equal("sha512="+base64.StdEncoding.EncodeToString(hmacHex(sha512.New, "secret", canonical)),
+		requests[0].header.Get(HeaderXSignature))
+
+	_, err = HMACTemplate("{{.Unknown")
+	c.Require().Error(err)
+}
+
+func (c *ClientSuite) Test_HMACSigning_Key_Rotation() {
+	c.T().Parallel()
+	var mu sync.Mutex
+	requests := make([]signedRequest, 0)
+	server := newHMACServer(&requests, &mu)
+	defer server.Close()
+
+	var current atomic.Value
+	current.Store(HMACKey{ID: "key-1", Secret: []byte("first")})
+	client := New().BasePath(server.URL).Use(HMACSigningMiddleware(HMACSigningConfig{
+		KeyProvider: func(context.Context) (HMACKey, error) {
+			return current.Load().(HMACKey), nil
+		},
+	}))
+
+	c.Require().NoError(client.Get("/").Send())
+	current.Store(HMACKey{ID: "key-2", Secret: []byte("second")})
+	c.Require().NoError(client.Get("/").Send())
+
+	c.Require().Len(requests, 2)
+	c.Require().Equal("key-1", requests[0].header.Get(HeaderXSignatureKeyID))
+	c.Require().Equal("key-2", requests[1].header.Get(HeaderXSignatureKeyID))
+
+	empty := New().BasePath(server.URL).Use(HMACSigningMiddleware(HMACSigningConfig{}))
+	c.Require().ErrorIs(empty.Get("/").Send(), ErrMissingSigningKey)
+}
+
+func (c *ClientSuite) Test_HMACSigning_Every_Retry_With_The_Body() {
+	c.T().Parallel()
+	var mu sync.Mutex
+	requests := make([]signedRequest, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, signedRequest{header: r.Header.Clone(), body: string(body)})
+		if len(requests) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clock := inputest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := New().
+		BasePath(server.URL).
+		Clock(clock).
+		Use(
+			RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 1, InitialBackoff: time.Minute}),
+			HMACSigningMiddleware(HMACSigningConfig{Key: HMACKey{Secret: []byte("secret")}}),
+		)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Put("/orders/1", BodyString("payload")).Send()
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	c.Require().NoError(<-done)
+
+	c.Require().Len(requests, 2)
+	for _, request := range requests {
+		c.Require().Equal("payload", request.body)
+	}
+	c.Require().NotEqual(requests[0].header.Get(HeaderXSignatureTimestamp),
+		requests[1].header.Get(HeaderXSignatureTimestamp))
+	c.Require().NotEqual(requests[0].header.Get(HeaderXSignature), requests[1].header.Get(HeaderXSignature))
+}
@@ -0,0 +1,232 @@
+package inpu
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	contextKeyHooks  = "inpu_hooks"
+	defaultRedirects = 10
+)
+
+// RequestEvent is passed to Hooks.OnRequest before every attempt of a request is sent.
+type RequestEvent struct {
+	Request *http.Request
+	// Attempt is the retry attempt, 0 for the first one.
+	Attempt int
+}
+
+// ResponseEvent is passed to Hooks.OnResponse when an attempt of a request receives a response.
+type ResponseEvent struct {
+	Request  *http.Request
+	Response *http.Response
+	Attempt  int
+	// Duration is the time from sending the attempt to receiving the response headers.
+	Duration time.Duration
+}
+
+// ErrorEvent is passed to Hooks.OnError when an attempt of a request fails without a response.
+type ErrorEvent struct {
+	Request  *http.Request
+	Err      error
+	Attempt  int
+	Duration time.Duration
+}
+
+// RetryEvent is passed to Hooks.OnRetry when the retry middleware decides to retry a request.
+type RetryEvent struct {
+	Request *http.Request
+	// Response and Err are the result of the failed attempt, one of them can be nil.
+	Response *http.Response
+	Err      error
+	// Attempt is the attempt that is going to be sent after Wait.
+	Attempt int
+	Wait    time.Duration
+}
+
+// RedirectEvent is passed to Hooks.OnRedirect before a redirect is followed.
+type RedirectEvent struct {
+	Request *http.Request
+	// Via are the requests made so far, the oldest first.
+	Via []*http.Request
+}
+
+// HandlerMatchedEvent is passed to Hooks.OnHandlerMatched when a status matcher matches the response,
+// just before its response handler is called.
+type HandlerMatchedEvent struct {
+	Request       *http.Request
+	Response      *http.Response
+	StatusMatcher StatusMatcher
+	Attempt       int
+	// Duration is the time from Send to the match, including all the attempts.
+	Duration time.Duration
+}
+
+// BulkheadEvent is passed to Hooks.OnBulkhead when ConcurrencyLimitMiddleware lets a request through or rejects it.
+type BulkheadEvent struct {
+	Request *http.Request
+	// Key is the key the request is limited by, the host by default.
+	Key string
+	// QueueDepth is the number of requests that were waiting when the request arrived.
+	QueueDepth int
+	// Wait is the time the request waited for a slot.
+	Wait time.Duration
+	// Err is ErrBulkheadFull or the context error when the request did not get a slot.
+	Err error
+}
+
+// CacheEvent is passed to Hooks.OnCacheHit and Hooks.OnCacheMiss when CacheMiddleware answers a request.
+type CacheEvent struct {
+	Request *http.Request
+	// Key is the key of the response in the CacheStore.
+	Key    string
+	Status CacheStatus
+	// Age is the age of the cached response, zero on a miss.
+	Age time.Duration
+}
+
+// Hooks observes the lifecycle of the requests of a client. Every hook is optional.
+// The hooks are called synchronously on the goroutine of the request, so they should return quickly.
+type Hooks struct {
+	OnRequest        func(event RequestEvent)
+	OnResponse       func(event ResponseEvent)
+	OnError          func(event ErrorEvent)
+	OnRetry          func(event RetryEvent)
+	OnRedirect       func(event RedirectEvent)
+	OnHandlerMatched func(event HandlerMatchedEvent)
+	OnBulkhead       func(event BulkheadEvent)
+	// OnCacheHit is called when the response is served from the cache: fresh, stale or revalidated.
+	OnCacheHit  func(event CacheEvent)
+	OnCacheMiss func(event CacheEvent)
+}
+
+type hookSet []Hooks
+
+func (h hookSet) request(event RequestEvent) {
+	for i := range h {
+		if h[i].OnRequest != nil {
+			h[i].OnRequest(event)
+		}
+	}
+}
+
+func (h hookSet) response(event ResponseEvent) {
+	for i := range h {
+		if h[i].OnResponse != nil {
+			h[i].OnResponse(event)
+		}
+	}
+}
+
+func (h hookSet) error(event ErrorEvent) {
+	for i := range h {
+		if h[i].OnError != nil {
+			h[i].OnError(event)
+		}
+	}
+}
+
+func (h hookSet) retry(event RetryEvent) {
+	for i := range h {
+		if h[i].OnRetry != nil {
+			h[i].OnRetry(event)
+		}
+	}
+}
+
+func (h hookSet) redirect(event RedirectEvent) {
+	for i := range h {
+		if h[i].OnRedirect != nil {
+			h[i].OnRedirect(event)
+		}
+	}
+}
+
+func (h hookSet) handlerMatched(event HandlerMatchedEvent) {
+	for i := range h {
+		if h[i].OnHandlerMatched != nil {
+			h[i].OnHandlerMatched(event)
+		}
+	}
+}
+
+func (h hookSet) bulkhead(event BulkheadEvent) {
+	for i := range h {
+		if h[i].OnBulkhead != nil {
+			h[i].OnBulkhead(event)
+		}
+	}
+}
+
+func (h hookSet) cacheHit(event CacheEvent) {
+	for i := range h {
+		if h[i].OnCacheHit != nil {
+			h[i].OnCacheHit(event)
+		}
+	}
+}
+
+func (h hookSet) cacheMiss(event CacheEvent) {
+	for i := range h {
+		if h[i].OnCacheMiss != nil {
+			h[i].OnCacheMiss(event)
+		}
+	}
+}
+
+func hooksFromContext(ctx context.Context) hookSet {
+	hooks, _ := ctx.Value(contextKeyHooks).(hookSet)
+
+	return hooks
+}
+
+// hooksTransport is the innermost transport of every client, so it observes every attempt. It does nothing when the
+// client has no hooks.
+type hooksTransport struct {
+	next http.RoundTripper
+}
+
+func (t *hooksTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hooks := hooksFromContext(req.Context())
+	if len(hooks) == 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	attempt := ExtractRetryAttemptFromContext(req.Context())
+	hooks.request(RequestEvent{Request: req, Attempt: attempt})
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+	if err != nil {
+		hooks.error(ErrorEvent{Request: req, Err: err, Attempt: attempt, Duration: duration})
+
+		return resp, err
+	}
+
+	hooks.response(ResponseEvent{Request: req, Response: resp, Attempt: attempt, Duration: duration})
+
+	return resp, nil
+}
+
+// checkRedirectWithHooks calls the OnRedirect hooks before the redirect policy of the client.
+func checkRedirectWithHooks(checkRedirect func(req *http.Request, via []*http.Request) error,
+) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		hooksFromContext(req.Context()).redirect(RedirectEvent{Request: req, Via: via})
+
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+
+		// the default policy of http.Client
+		if len(via) >= defaultRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, defaultRedirects)
+		}
+
+		return nil
+	}
+}
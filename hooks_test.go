@@ -0,0 +1,143 @@
+package inpu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+)
+
+func (c *ClientSuite) Test_Hooks_Lifecycle() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/final" {
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+		http.Redirect(w, r, "/final", http.StatusFound)
+	}))
+	defer server.Close()
+
+	requests := make([]int, 0)
+	responses := make([]int, 0)
+	retries := make([]RetryEvent, 0)
+	redirects := make([]RedirectEvent, 0)
+	matches := make([]HandlerMatchedEvent, 0)
+	orders := make([]string, 0)
+	client := New().
+		BasePath(server.URL).
+		Use(RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond})).
+		Hooks(Hooks{
+			OnRequest: func(event RequestEvent) {
+				requests = append(requests, event.Attempt)
+			},
+			OnResponse: func(event ResponseEvent) {
+				responses = append(responses, event.Response.StatusCode)
+				c.Require().Positive(event.Duration)
+			},
+			OnRetry: func(event RetryEvent) {
+				retries = append(retries, event)
+			},
+			OnRedirect: func(event RedirectEvent) {
+				redirects = append(redirects, event)
+			},
+			OnHandlerMatched: func(event HandlerMatchedEvent) {
+				matches = append(matches, event)
+				orders = append(orders, "first")
+			},
+		}).
+		Hooks(Hooks{
+			OnHandlerMatched: func(event HandlerMatchedEvent) {
+				orders = append(orders, "second")
+			},
+		})
+
+	err := client.Get("/start").
+		OnOk(ThenDoNothing).
+		OnAny(ThenReturnDefaultError).
+		Send()
+
+	c.Require().NoError(err)
+	c.Require().Equal([]int{0, 1, 0}, requests)
+	c.Require().Equal([]int{http.StatusServiceUnavailable, http.StatusFound, http.StatusOK}, responses)
+	c.Require().Len(retries, 1)
+	c.Require().Equal(1, retries[0].Attempt)
+	c.Require().Equal(http.StatusServiceUnavailable, retries[0].Response.StatusCode)
+	c.Require().Equal(time.Millisecond, retries[0].Wait)
+	c.Require().Len(redirects, 1)
+	c.Require().Equal("/final", redirects[0].Request.URL.Path)
+	c.Require().Len(redirects[0].Via, 1)
+	c.Require().Len(matches, 1)
+	c.Require().Equal(StatusIsOk, matches[0].StatusMatcher)
+	c.Require().Equal("/final", matches[0].Request.URL.Path)
+	c.Require().Positive(matches[0].Duration)
+	c.Require().Equal([]string{"first", "second"}, orders)
+}
+
+func (c *ClientSuite) Test_Hooks_OnError() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	errorEvents := make([]ErrorEvent, 0)
+	err := New().
+		Hooks(Hooks{
+			OnError: func(event ErrorEvent) {
+				errorEvents = append(errorEvents, event)
+			},
+			OnHandlerMatched: func(event HandlerMatchedEvent) {
+				c.Fail("no handler should match")
+			},
+		}).
+		Get(server.URL).
+		OnAny(ThenDoNothing).
+		Send()
+
+	c.Require().ErrorIs(err, ErrConnectionFailed)
+	c.Require().Len(errorEvents, 1)
+	c.Require().Error(errorEvents[0].Err)
+	c.Require().Zero(errorEvents[0].Attempt)
+}
+
+func (c *ClientSuite) Test_Hooks_Added_After_First_Request() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/final" {
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+		http.Redirect(w, r, "/final", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL).FollowRedirects(2)
+	c.Require().NoError(client.Get("/start").OnAny(ThenDoNothing).Send())
+
+	requests := make([]string, 0)
+	responses := make([]int, 0)
+	redirects := make([]RedirectEvent, 0)
+	client.Hooks(Hooks{
+		OnRequest: func(event RequestEvent) {
+			requests = append(requests, event.Request.URL.Path)
+		},
+		OnResponse: func(event ResponseEvent) {
+			responses = append(responses, event.Response.StatusCode)
+		},
+		OnRedirect: func(event RedirectEvent) {
+			redirects = append(redirects, event)
+		},
+	})
+
+	c.Require().NoError(client.Get("/start").OnAny(ThenDoNothing).Send())
+	c.Require().Equal([]string{"/start", "/final"}, requests)
+	c.Require().Equal([]int{http.StatusFound, http.StatusOK}, responses)
+	c.Require().Len(redirects, 1)
+}
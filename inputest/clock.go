@@ -0,0 +1,117 @@
+// Package inputest has helpers to test the code that uses inpu.
+package inputest
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock whose time only moves with Advance and Set. It implements inpu.Clock, so the retry waits,
+// the cache freshness, the rate limiter, the circuit breaker and the token refresh can be tested without sleeping.
+// Usage:
+//
+//	clock := inputest.NewFakeClock(time.Now())
+//	client := inpu.New().Clock(clock)
+//	clock.Advance(time.Minute)
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	until time.Time
+	ch    chan time.Time
+}
+
+// NewFakeClock creates a fake clock that is stopped at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	clock := &FakeClock{now: now}
+	clock.cond = sync.NewCond(&clock.mu)
+
+	return clock
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After returns a channel that receives the time once the clock is advanced by d. It fires immediately if d is not
+// positive.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+
+		return ch
+	}
+
+	c.waiters = append(c.waiters, fakeWaiter{until: c.now.Add(d), ch: ch})
+	c.cond.Broadcast()
+
+	return ch
+}
+
+// Advance moves the clock forward by d and fires the waiters that are due, in the order of their time.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setLocked(c.now.Add(d))
+}
+
+// Set moves the clock to now and fires the waiters that are due. The clock can be set to the past, then no waiter
+// fires.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setLocked(now)
+}
+
+// Waiters returns the number of channels returned by After that have not fired yet.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.waiters)
+}
+
+// BlockUntil waits until n channels returned by After are waiting to fire, so the test can advance the clock once the
+// code under test waits for it.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *FakeClock) setLocked(now time.Time) {
+	c.now = now
+
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].until.Before(c.waiters[j].until)
+	})
+
+	pending := c.waiters[:0]
+	for _, waiter := range c.waiters {
+		if waiter.until.After(now) {
+			pending = append(pending, waiter)
+
+			continue
+		}
+		waiter.ch <- waiter.until
+	}
+	c.waiters = pending
+	c.cond.Broadcast()
+}
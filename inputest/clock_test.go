@@ -0,0 +1,65 @@
+package inputest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClock_Advance_Fires_Due_Waiters(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	second := clock.After(2 * time.Second)
+	first := clock.After(time.Second)
+	require.Equal(t, 2, clock.Waiters())
+
+	clock.Advance(time.Second)
+	require.Equal(t, start.Add(time.Second), <-first)
+	require.Empty(t, second)
+	require.Equal(t, 1, clock.Waiters())
+
+	clock.Advance(time.Second)
+	require.Equal(t, start.Add(2*time.Second), <-second)
+	require.Equal(t, start.Add(2*time.Second), clock.Now())
+	require.Zero(t, clock.Waiters())
+}
+
+func TestFakeClock_After_Non_Positive_Fires_Immediately(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	require.Equal(t, start, <-clock.After(0))
+	require.Zero(t, clock.Waiters())
+}
+
+func TestFakeClock_Set(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	fired := clock.After(time.Hour)
+
+	clock.Set(start.Add(-time.Hour))
+	require.Empty(t, fired)
+
+	clock.Set(start.Add(2 * time.Hour))
+	require.Equal(t, start.Add(time.Hour), <-fired)
+}
+
+func TestFakeClock_BlockUntil(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		<-clock.After(time.Minute)
+		close(done)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-done
+}
@@ -3,10 +3,14 @@
 package inpu
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 )
 
+// jsonRawValue is an encoded JSON value that is decoded later, its numbers keep their precision.
+type jsonRawValue = json.RawMessage
+
 func jsonMarshal(v any) ([]byte, error) {
 	return json.Marshal(v)
 }
@@ -14,3 +18,13 @@ func jsonMarshal(v any) ([]byte, error) {
 func jsonUnmarshalFromReader(r io.Reader, v any) error {
 	return json.NewDecoder(r).Decode(v)
 }
+
+// jsonCompact removes the insignificant whitespace of the value, so equal values have the same bytes.
+func jsonCompact(v jsonRawValue) (jsonRawValue, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, v); err != nil {
+		return nil, err
+	}
+
+	return compact.Bytes(), nil
+}
@@ -3,10 +3,14 @@
 package inpu
 
 import (
+	"encoding/json/jsontext"
 	"encoding/json/v2"
 	"io"
 )
 
+// jsonRawValue is an encoded JSON value that is decoded later, its numbers keep their precision.
+type jsonRawValue = jsontext.Value
+
 func jsonMarshal(v any) ([]byte, error) {
 	return json.Marshal(v)
 }
@@ -14,3 +18,13 @@ func jsonMarshal(v any) ([]byte, error) {
 func jsonUnmarshalFromReader(r io.Reader, v any) error {
 	return json.UnmarshalRead(r, v)
 }
+
+// jsonCompact removes the insignificant whitespace of the value, so equal values have the same bytes.
+func jsonCompact(v jsonRawValue) (jsonRawValue, error) {
+	compact := v.Clone()
+	if err := compact.Compact(); err != nil {
+		return nil, err
+	}
+
+	return compact, nil
+}
@@ -2,6 +2,7 @@ package inpu
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -43,7 +44,11 @@ type loggingMiddleware struct {
 	verbose        bool
 	disabled       bool
 	maxBodyLogSize int
-	next           http.RoundTripper
+}
+
+type loggingTransport struct {
+	*loggingMiddleware
+	next http.RoundTripper
 }
 
 // NewLoggingMiddleware creates a logging middleware with the provided options.
@@ -77,12 +82,10 @@ func (t *loggingMiddleware) Priority() int {
 }
 
 func (t *loggingMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
-	t.next = next
-
-	return t
+	return &loggingTransport{loggingMiddleware: t, next: next}
 }
 
-func (t *loggingMiddleware) RoundTrip(req *http.Request) (*http.Response, error) {
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	if t.disabled {
 		return t.next.RoundTrip(req)
 	}
@@ -91,7 +94,7 @@ func (t *loggingMiddleware) RoundTrip(req *http.Request) (*http.Response, error)
 	start := time.Now()
 
 	// Log request
-	logger.Info(ctx, "→ [%s] %s", req.Method, req.URL.Redacted())
+	logger.Info(ctx, "→ [%s] %s%s", req.Method, req.URL.Redacted(), hedgeLabel(ctx))
 
 	if t.verbose {
 		logger.Info(ctx, "  Headers: %v", headersToString(req.Header))
@@ -113,12 +116,14 @@ func (t *loggingMiddleware) RoundTrip(req *http.Request) (*http.Response, error)
 			resp.Body = io.NopCloser(bytes.NewBuffer(body))
 			logger.Info(ctx, "  Error Response Body: %s", t.truncateBody(body))
 		}
-		logger.Error(ctx, err, "← [%s] %s - ERROR: %v (took %v)", req.Method, req.URL.Redacted(), err, duration)
+		logger.Error(ctx, err, "← [%s] %s%s - ERROR: %v (took %v)", req.Method, req.URL.Redacted(), hedgeLabel(ctx),
+			err, duration)
 
 		return resp, err
 	}
 
-	logger.Info(ctx, "← [%s] %s - Status: %d - Duration: %v", req.Method, req.URL.Redacted(), resp.StatusCode, duration)
+	logger.Info(ctx, "← [%s] %s%s - Status: %d - Duration: %v", req.Method, req.URL.Redacted(), hedgeLabel(ctx),
+		resp.StatusCode, duration)
 
 	if t.verbose {
 		logger.Info(ctx, "  Response Headers: %v", headersToString(resp.Header))
@@ -152,3 +157,12 @@ func headersToString(headers http.Header) string {
 	}
 	return strings.Join(parts, "; ")
 }
+
+// hedgeLabel tells the attempts of a hedged request apart, it is empty for the first attempt.
+func hedgeLabel(ctx context.Context) string {
+	if hedge := ExtractHedgeAttemptFromContext(ctx); hedge > 0 {
+		return fmt.Sprintf(" (hedge %d)", hedge)
+	}
+
+	return ""
+}
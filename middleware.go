@@ -1,7 +1,16 @@
 package inpu
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
 
+// Middleware wraps the transport of a client.
+// A middleware can be shared by many clients, so Apply must not modify the middleware. It must return a new
+// http.RoundTripper that calls next, and it may be called concurrently.
 type Middleware interface {
 	ID() string
 	Priority() int
@@ -13,3 +22,187 @@ type (
 	ResponseModifier func(response *http.Response, server error) (*http.Response, error)
 	ErrorHandler     func(serverError error) error
 )
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as http.RoundTripper.
+// Usage:
+//
+//	func (m *headerMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
+//		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+//			req.Header.Set("X-Custom", m.value)
+//			return next.RoundTrip(req)
+//		})
+//	}
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// addMiddlewares appends the middlewares to mws, a middleware replaces the one with the same ID.
+func addMiddlewares(mws []Middleware, toAdd ...Middleware) []Middleware {
+	for i := range toAdd {
+		if toAdd[i] == nil {
+			continue
+		}
+		index := slices.IndexFunc(mws, func(m Middleware) bool {
+			return m.ID() == toAdd[i].ID()
+		})
+
+		if index != -1 {
+			mws = append(mws[:index], mws[index+1:]...)
+		}
+
+		mws = append(mws, toAdd[i])
+	}
+
+	return mws
+}
+
+// OrderConstraint places a middleware relative to another middleware in the chain.
+type OrderConstraint func(*orderedMiddleware)
+
+// Before places the middleware before the middleware with the id, it sees the request first and wraps it.
+func Before(id string) OrderConstraint {
+	return func(m *orderedMiddleware) {
+		m.before = append(m.before, id)
+	}
+}
+
+// After places the middleware after the middleware with the id, it sees the request after it and is wrapped by it.
+func After(id string) OrderConstraint {
+	return func(m *orderedMiddleware) {
+		m.after = append(m.after, id)
+	}
+}
+
+type orderedMiddleware struct {
+	Middleware
+	before []string
+	after  []string
+}
+
+// Ordered adds order constraints to the middleware. Priorities are still used for the middlewares without a
+// constraint between them, and constraints on middlewares that are not in the chain are ignored.
+// Send returns ErrMiddlewareCycle if the constraints contradict each other.
+// Usage:
+//
+//	client := New().Use(
+//		RetryMiddleware(3),
+//		Ordered(otel.NewMiddleware(), After("retry-middleware")), // a span for every attempt
+//	)
+func Ordered(mw Middleware, constraints ...OrderConstraint) Middleware {
+	ordered := &orderedMiddleware{Middleware: mw}
+	for _, constraint := range constraints {
+		constraint(ordered)
+	}
+
+	return ordered
+}
+
+// MiddlewareChain is the resolved order of the middlewares, the first one sees the request first.
+type MiddlewareChain []Middleware
+
+// IDs returns the IDs of the middlewares in the chain order.
+func (c MiddlewareChain) IDs() []string {
+	ids := make([]string, len(c))
+	for i := range c {
+		ids[i] = c[i].ID()
+	}
+
+	return ids
+}
+
+// String returns the chain from the request to the transport, one middleware per line.
+func (c MiddlewareChain) String() string {
+	var sb strings.Builder
+	sb.WriteString("request\n")
+	for _, mw := range c {
+		fmt.Fprintf(&sb, "  -> %s (priority %d)\n", mw.ID(), mw.Priority())
+	}
+	sb.WriteString("  -> transport")
+
+	return sb.String()
+}
+
+// resolveMiddlewares orders the middlewares by descending priority and then moves them to satisfy the
+// Before and After constraints. Among the middlewares that can be placed next, the one that comes first by
+// priority is picked, so the order only changes where a constraint requires it.
+func resolveMiddlewares(mws []Middleware) (MiddlewareChain, error) {
+	sorted := slices.Clone(mws)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority() < sorted[j].Priority()
+	})
+	slices.Reverse(sorted)
+
+	indexes := make(map[string]int, len(sorted))
+	for i := range sorted {
+		indexes[sorted[i].ID()] = i
+	}
+
+	// edges[i] are the middlewares that must come after sorted[i]
+	edges := make([][]int, len(sorted))
+	inDegrees := make([]int, len(sorted))
+	addEdge := func(from, to int) {
+		edges[from] = append(edges[from], to)
+		inDegrees[to]++
+	}
+	for i := range sorted {
+		ordered, ok := sorted[i].(*orderedMiddleware)
+		if !ok {
+			continue
+		}
+		for _, id := range ordered.before {
+			if j, ok := indexes[id]; ok {
+				addEdge(i, j)
+			}
+		}
+		for _, id := range ordered.after {
+			if j, ok := indexes[id]; ok {
+				addEdge(j, i)
+			}
+		}
+	}
+
+	chain := make(MiddlewareChain, 0, len(sorted))
+	placed := make([]bool, len(sorted))
+	for len(chain) < len(sorted) {
+		next := slices.IndexFunc(sorted, func(m Middleware) bool {
+			i := indexes[m.ID()]
+
+			return !placed[i] && inDegrees[i] == 0
+		})
+		if next == -1 {
+			remaining := make([]string, 0)
+			for i := range sorted {
+				if !placed[i] {
+					remaining = append(remaining, sorted[i].ID())
+				}
+			}
+
+			return nil, fmt.Errorf("%w: %s", ErrMiddlewareCycle, strings.Join(remaining, ", "))
+		}
+
+		placed[next] = true
+		chain = append(chain, sorted[next])
+		for _, j := range edges[next] {
+			inDegrees[j]--
+		}
+	}
+
+	return chain, nil
+}
+
+// chainMiddlewares resolves the order of the middlewares and wraps the transport with them,
+// so the first middleware of the chain is the outermost one.
+func chainMiddlewares(transport http.RoundTripper, mws []Middleware) (http.RoundTripper, error) {
+	chain, err := resolveMiddlewares(mws)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		transport = chain[i].Apply(transport)
+	}
+
+	return transport, nil
+}
@@ -2,9 +2,13 @@ package inpu
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
 )
 
 func (c *ClientSuite) Test_Client_No_Duplicate_Middleware() {
@@ -70,3 +74,229 @@ func (c *ClientSuite) Test_IgnoreNilMiddleware() {
 	c.Require().NoError(err)
 	c.Require().Len(client.mws, 1)
 }
+
+func (c *ClientSuite) Test_Middleware_Shared_Across_Clients() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		w.Write([]byte(request.Header.Get("X-Client")))
+	}))
+	defer server.Close()
+
+	shared := []Middleware{
+		NewLoggingMiddleware(WithDisabled()),
+		RetryMiddleware(1),
+		RequestIDMiddleware(),
+		ErrorHandlerMiddleware(func(serverError error) error { return serverError }),
+	}
+
+	clients := make([]*Client, 20)
+	for i := range clients {
+		clientID := strconv.Itoa(i)
+		clients[i] = New().
+			BasePath(server.URL).
+			Use(shared...).
+			Use(RequestModifierMiddleware(func(req *http.Request) (*http.Request, error) {
+				req.Header.Set("X-Client", clientID)
+
+				return req, nil
+			}, "client-id-middleware", 0))
+	}
+
+	var wg sync.WaitGroup
+	for i := range clients {
+		for range 5 {
+			wg.Go(func() {
+				var body []byte
+				err := clients[i].Get("/").
+					OnOk(func(response *http.Response) error {
+						var err error
+						body, err = io.ReadAll(response.Body)
+
+						return err
+					}).
+					OnAny(ThenReturnDefaultError).
+					Send()
+
+				c.Require().NoError(err)
+				c.Require().Equal(strconv.Itoa(i), string(body))
+			})
+		}
+	}
+	wg.Wait()
+}
+
+func (c *ClientSuite) Test_Request_Middlewares() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		calls.Add(1)
+		w.Header().Set("X-Debug", request.Header.Get("X-Debug"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	retry := func(maxRetries int) Middleware {
+		return RetryMiddlewareWithConfig(RetryConfig{MaxRetries: maxRetries, BackoffMultiplier: 1})
+	}
+	debug := RequestModifierMiddleware(func(req *http.Request) (*http.Request, error) {
+		req.Header.Set("X-Debug", "true")
+
+		return req, nil
+	}, "debug-middleware", 10)
+
+	client := New().BasePath(server.URL).Use(retry(1))
+
+	var debugHeader string
+	err := client.Get("/").
+		Use(retry(3), debug).
+		OnAny(func(response *http.Response) error {
+			debugHeader = response.Header.Get("X-Debug")
+
+			return nil
+		}).
+		Send()
+	c.Require().NoError(err)
+	c.Require().EqualValues(4, calls.Load())
+	c.Require().Equal("true", debugHeader)
+
+	calls.Store(0)
+	err = client.Get("/").
+		OnAny(func(response *http.Response) error {
+			debugHeader = response.Header.Get("X-Debug")
+
+			return nil
+		}).
+		Send()
+	c.Require().NoError(err)
+	c.Require().EqualValues(2, calls.Load())
+	c.Require().Empty(debugHeader)
+	c.Require().Len(client.mws, 1)
+}
+
+func (c *ClientSuite) Test_Request_Middlewares_Use_The_Middlewares_Of_The_First_Request() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		w.Header().Set("X-Late", request.Header.Get("X-Late"))
+	}))
+	defer server.Close()
+
+	late := RequestModifierMiddleware(func(req *http.Request) (*http.Request, error) {
+		req.Header.Set("X-Late", "true")
+
+		return req, nil
+	}, "late-middleware", 10)
+	client := New().BasePath(server.URL)
+	c.Require().NoError(client.Get("/").Send())
+
+	// the chain of the client is built, the request with its own middlewares must behave the same
+	client.Use(late)
+	for _, req := range []*Req{client.Get("/"), client.Get("/").Use(RequestIDMiddleware())} {
+		var lateHeader string
+		err := req.OnAny(func(response *http.Response) error {
+			lateHeader = response.Header.Get("X-Late")
+
+			return nil
+		}).Send()
+		c.Require().NoError(err)
+		c.Require().Empty(lateHeader)
+	}
+}
+
+func (c *ClientSuite) Test_Request_Middlewares_Without_Client() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		c.Require().NotEmpty(request.Header.Get(HeaderXRequestID))
+	}))
+	defer server.Close()
+
+	err := Get(server.URL).
+		Use(nil, RequestIDMiddleware()).
+		OnAny(ThenReturnDefaultError).
+		OnOk(ThenDoNothing).
+		Send()
+
+	c.Require().NoError(err)
+}
+
+func (c *ClientSuite) Test_Middlewares_Resolved_By_Priority() {
+	c.T().Parallel()
+	client := New().Use(LoggingMiddleware(false, false), RetryMiddleware(1), RequestIDMiddleware())
+
+	chain, err := client.Middlewares()
+
+	c.Require().NoError(err)
+	c.Require().Equal([]string{"request-modifier-middleware", "retry-middleware", "default-logging-middleware"},
+		chain.IDs())
+	c.Require().Equal("request\n"+
+		"  -> request-modifier-middleware (priority 100)\n"+
+		"  -> retry-middleware (priority 25)\n"+
+		"  -> default-logging-middleware (priority 1)\n"+
+		"  -> transport", chain.String())
+}
+
+func (c *ClientSuite) Test_Middlewares_Before_And_After_Constraints() {
+	c.T().Parallel()
+	chain, err := New().
+		Use(
+			Ordered(LoggingMiddleware(false, false), Before("retry-middleware")),
+			RetryMiddleware(1),
+			Ordered(RequestIDMiddleware(), After("retry-middleware"), Before("unknown-middleware")),
+		).
+		Middlewares()
+
+	c.Require().NoError(err)
+	c.Require().Equal([]string{"default-logging-middleware", "retry-middleware", "request-modifier-middleware"},
+		chain.IDs())
+}
+
+func (c *ClientSuite) Test_Middlewares_Constraints_Change_Execution_Order() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	calls := make([]string, 0)
+	recorder := func(id string, priority int) Middleware {
+		return RequestModifierMiddleware(func(req *http.Request) (*http.Request, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, id)
+
+			return req, nil
+		}, id, priority)
+	}
+
+	client := New().
+		BasePath(server.URL).
+		Use(recorder("outer", 10), Ordered(recorder("inner", 1), Before("outer")))
+	err := client.Get("/").Send()
+	c.Require().NoError(err)
+	c.Require().Equal([]string{"inner", "outer"}, calls)
+
+	calls = calls[:0]
+	err = client.Get("/").Use(recorder("inner", 1)).Send()
+	c.Require().NoError(err)
+	c.Require().Equal([]string{"outer", "inner"}, calls)
+}
+
+func (c *ClientSuite) Test_Middlewares_Cycle() {
+	c.T().Parallel()
+	client := New().
+		Use(
+			Ordered(LoggingMiddleware(false, false), Before("retry-middleware")),
+			Ordered(RetryMiddleware(1), Before("default-logging-middleware")),
+			RequestIDMiddleware(),
+		)
+
+	_, err := client.Middlewares()
+	c.Require().ErrorIs(err, ErrMiddlewareCycle)
+	c.Require().ErrorContains(err, "retry-middleware, default-logging-middleware")
+
+	err = client.Get("http://localhost").Send()
+	c.Require().ErrorIs(err, ErrMiddlewareCycle)
+
+	err = New().Use(Ordered(RetryMiddleware(1), After("request-modifier-middleware"))).Get("http://localhost").
+		Use(Ordered(RequestIDMiddleware(), After("retry-middleware"))).
+		Send()
+	c.Require().ErrorIs(err, ErrMiddlewareCycle)
+}
@@ -0,0 +1,102 @@
+package awssigv4
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/denizgursoy/inpu"
+)
+
+const defaultExpiryWindow = 5 * time.Minute
+
+var ErrMissingCredentials = errors.New("aws credentials are not set")
+
+// Credentials are the AWS credentials the requests are signed with.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials, it is sent in the X-Amz-Security-Token header.
+	SessionToken string
+	// Expires is when temporary credentials expire. Zero means they do not expire.
+	Expires time.Time
+}
+
+// CredentialsProvider returns the credentials to sign a request with. It must be safe for concurrent use.
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsProviderFunc is an adapter to use a function as a CredentialsProvider.
+type CredentialsProviderFunc func(ctx context.Context) (Credentials, error)
+
+func (f CredentialsProviderFunc) Retrieve(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// StaticCredentials always returns the same credentials.
+func StaticCredentials(accessKeyID, secretAccessKey, sessionToken string) CredentialsProvider {
+	return CredentialsProviderFunc(func(context.Context) (Credentials, error) {
+		return Credentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			SessionToken:    sessionToken,
+		}, nil
+	})
+}
+
+// EnvCredentials reads the credentials from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// every time they are retrieved.
+func EnvCredentials() CredentialsProvider {
+	return CredentialsProviderFunc(func(context.Context) (Credentials, error) {
+		credentials := Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+			return Credentials{}, ErrMissingCredentials
+		}
+
+		return credentials, nil
+	})
+}
+
+type cachedCredentials struct {
+	provider     CredentialsProvider
+	expiryWindow time.Duration
+	mu           sync.Mutex
+	credentials  *Credentials
+}
+
+// CachedCredentials caches the credentials of the provider and retrieves new ones expiryWindow before they expire,
+// like the temporary credentials of STS or of a container role. Default expiryWindow is 5 minutes. The expiry is
+// checked with the clock of the request, see inpu.Client.Clock.
+func CachedCredentials(provider CredentialsProvider, expiryWindow time.Duration) CredentialsProvider {
+	if expiryWindow <= 0 {
+		expiryWindow = defaultExpiryWindow
+	}
+
+	return &cachedCredentials{provider: provider, expiryWindow: expiryWindow}
+}
+
+func (c *cachedCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := inpu.ExtractClockFromContext(ctx).Now()
+	if c.credentials != nil &&
+		(c.credentials.Expires.IsZero() || now.Add(c.expiryWindow).Before(c.credentials.Expires)) {
+		return *c.credentials, nil
+	}
+
+	credentials, err := c.provider.Retrieve(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	c.credentials = &credentials
+
+	return credentials, nil
+}
@@ -0,0 +1,9 @@
+module github.com/denizgursoy/inpu/middlewares/awssigv4
+
+go 1.25.0
+
+require github.com/denizgursoy/inpu v1.4.0
+
+require github.com/google/uuid v1.6.0 // indirect
+
+replace github.com/denizgursoy/inpu => ../..
@@ -0,0 +1,143 @@
+package awssigv4
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/denizgursoy/inpu"
+	"github.com/denizgursoy/inpu/inputest"
+)
+
+func TestSigV4Middleware_Signs_Every_Retry(t *testing.T) {
+	var mu sync.Mutex
+	dates := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		dates = append(dates, r.Header.Get(HeaderAmzDate))
+
+		// the server signs the request again to check the signature
+		date, _ := time.Parse(timeFormat, r.Header.Get(HeaderAmzDate))
+		expected := r.Clone(r.Context())
+		expected.URL.Host = r.Host
+		// the transport adds headers after the signature, like Accept-Encoding, they are not signed
+		expected.Header = http.Header{HeaderAmzDate: r.Header.Values(HeaderAmzDate)}
+		signer := &Signer{Region: "eu-west-1", Service: "execute-api"}
+		if err := signer.Sign(expected, s3Credentials, date); err != nil ||
+			expected.Header.Get("Authorization") != r.Header.Get("Authorization") {
+			t.Errorf("invalid signature %s", r.Header.Get("Authorization"))
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		if len(dates) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clock := inputest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := inpu.New().
+		BasePath(server.URL).
+		Clock(clock).
+		Use(
+			inpu.RetryMiddlewareWithConfig(inpu.RetryConfig{MaxRetries: 1, InitialBackoff: time.Minute}),
+			NewSigV4Middleware(Config{
+				Region:      "eu-west-1",
+				Service:     "execute-api",
+				Credentials: StaticCredentials(s3Credentials.AccessKeyID, s3Credentials.SecretAccessKey, ""),
+			}),
+		)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Get("/prod/items").QueryInt32("limit", 10).OnOk(inpu.ThenDoNothing).
+			On(inpu.StatusAny, inpu.ThenReturnDefaultError).Send()
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	if err := <-done; err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	expected := []string{"20240101T000000Z", "20240101T000100Z"}
+	if len(dates) != 2 || dates[0] != expected[0] || dates[1] != expected[1] {
+		t.Fatalf("expected the dates %v, got %v", expected, dates)
+	}
+}
+
+func TestSigV4Middleware_Presign(t *testing.T) {
+	middleware := NewSigV4Middleware(Config{
+		Region:      "us-east-1",
+		Service:     "s3",
+		Credentials: StaticCredentials(s3Credentials.AccessKeyID, s3Credentials.SecretAccessKey, ""),
+	})
+
+	clock := inputest.NewFakeClock(time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC))
+	ctx := inpu.ContextWithClock(context.Background(), clock)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://examplebucket.s3.amazonaws.com/test.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	presigned, err := middleware.Presign(req, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := presigned.Query().Get("X-Amz-Signature"); got !=
+		"aeeed9bbccd4d02ee5c0109b86d86835f995330da4c265957d157751f604d404" {
+		t.Fatalf("unexpected signature %s", got)
+	}
+}
+
+func TestCachedCredentials_Refresh(t *testing.T) {
+	clock := inputest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ctx := inpu.ContextWithClock(context.Background(), clock)
+
+	retrieved := 0
+	provider := CachedCredentials(CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		retrieved++
+
+		return Credentials{
+			AccessKeyID:     "AKID",
+			SecretAccessKey: "secret",
+			SessionToken:    "token",
+			Expires:         inpu.ExtractClockFromContext(ctx).Now().Add(time.Hour),
+		}, nil
+	}), 5*time.Minute)
+
+	for _, advance := range []time.Duration{0, 50 * time.Minute, 5 * time.Minute} {
+		clock.Advance(advance)
+		if _, err := provider.Retrieve(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if retrieved != 2 {
+		t.Fatalf("expected the credentials to be retrieved 2 times, got %d", retrieved)
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := EnvCredentials().Retrieve(context.Background()); err != ErrMissingCredentials {
+		t.Fatalf("expected ErrMissingCredentials, got %v", err)
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	credentials, err := EnvCredentials().Retrieve(context.Background())
+	if err != nil || credentials.AccessKeyID != "AKID" || credentials.SecretAccessKey != "secret" {
+		t.Fatalf("unexpected credentials %+v, %v", credentials, err)
+	}
+}
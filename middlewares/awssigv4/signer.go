@@ -0,0 +1,288 @@
+package awssigv4
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/denizgursoy/inpu"
+)
+
+const (
+	algorithm       = "AWS4-HMAC-SHA256"
+	timeFormat      = "20060102T150405Z"
+	dateFormat      = "20060102"
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+	HeaderAmzDate          = "X-Amz-Date"
+	HeaderAmzContentSHA256 = "X-Amz-Content-Sha256"
+	HeaderAmzSecurityToken = "X-Amz-Security-Token"
+
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body.
+var emptyPayloadHash = hashHex(nil)
+
+// ignoredHeaders are not signed, a proxy or an inner middleware may change them.
+var ignoredHeaders = []string{"authorization", "user-agent", "x-amzn-trace-id", "expect", "transfer-encoding"}
+
+// Signer signs requests with AWS Signature Version 4.
+type Signer struct {
+	// Region is the AWS region of the endpoint, like us-east-1.
+	Region string
+	// Service is the signing name of the service, like s3 or execute-api.
+	Service string
+	// UnsignedPayload signs the requests with UNSIGNED-PAYLOAD instead of the hash of the body, so a streamed body
+	// is not read to sign it. Only some services, like S3, accept it.
+	UnsignedPayload bool
+	// DisableDoubleEscaping escapes the path once. It is always disabled for S3, which expects the path escaped once.
+	DisableDoubleEscaping bool
+}
+
+// Sign adds the Authorization, X-Amz-Date and, for temporary credentials, X-Amz-Security-Token headers to the
+// request. The body is hashed from GetBody, a body without GetBody is read into memory unless UnsignedPayload is set.
+// For S3 and for an unsigned payload, the X-Amz-Content-Sha256 header is added too.
+func (s *Signer) Sign(req *http.Request, credentials Credentials, now time.Time) error {
+	now = now.UTC()
+	req.Header.Del(inpu.HeaderAuthorization)
+	req.Header.Set(HeaderAmzDate, now.Format(timeFormat))
+	if credentials.SessionToken != "" {
+		req.Header.Set(HeaderAmzSecurityToken, credentials.SessionToken)
+	} else {
+		req.Header.Del(HeaderAmzSecurityToken)
+	}
+
+	payloadHash, err := s.payloadHash(req)
+	if err != nil {
+		return err
+	}
+	if s.Service == "s3" || payloadHash == UnsignedPayload {
+		req.Header.Set(HeaderAmzContentSHA256, payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.signature(credentials, now, scope, canonicalRequest)
+	req.Header.Set(inpu.HeaderAuthorization, fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, credentials.AccessKeyID, scope, signedHeaders, signature))
+
+	return nil
+}
+
+// Presign returns the URL of the request with the signature in the query, so it can be used without the credentials
+// until it expires, like a download link of an S3 object. Only the host and the headers of the request are signed,
+// the payload is UNSIGNED-PAYLOAD. The expiry is at most 7 days.
+func (s *Signer) Presign(req *http.Request, credentials Credentials, now time.Time, expires time.Duration,
+) (*url.URL, error) {
+	if expires <= 0 || expires > maxPresignExpiry {
+		return nil, fmt.Errorf("presigned url expiry must be between 1 second and 7 days: %v", expires)
+	}
+
+	now = now.UTC()
+	scope := s.scope(now)
+	signedHeaders, canonicalHeaders := canonicalHeaders(req)
+
+	presigned := *req.URL
+	query := presigned.Query()
+	query.Set("X-Amz-Algorithm", algorithm)
+	query.Set("X-Amz-Credential", credentials.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(timeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	if credentials.SessionToken != "" {
+		query.Set(HeaderAmzSecurityToken, credentials.SessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req.URL),
+		canonicalQuery(query),
+		canonicalHeaders,
+		signedHeaders,
+		UnsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(credentials, now, scope, canonicalRequest))
+	presigned.RawQuery = canonicalQuery(query)
+
+	return &presigned, nil
+}
+
+func (s *Signer) scope(now time.Time) string {
+	return strings.Join([]string{now.Format(dateFormat), s.Region, s.Service, "aws4_request"}, "/")
+}
+
+func (s *Signer) signature(credentials Credentials, now time.Time, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{algorithm, now.Format(timeFormat), scope, hashHex([]byte(canonicalRequest))},
+		"\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), now.Format(dateFormat))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// payloadHash returns the hex SHA-256 of the body without consuming it.
+func (s *Signer) payloadHash(req *http.Request) (string, error) {
+	if hash := req.Header.Get(HeaderAmzContentSHA256); hash != "" {
+		return hash, nil
+	}
+	if s.UnsignedPayload {
+		return UnsignedPayload, nil
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return emptyPayloadHash, nil
+	}
+
+	if req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		return hashHex(body), nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// canonicalURI escapes every segment of the path, twice for the services other than S3.
+func (s *Signer) canonicalURI(u *url.URL) string {
+	path := u.Path
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+		if s.Service != "s3" && !s.DisableDoubleEscaping {
+			segments[i] = escape(segments[i])
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery escapes the parameters and sorts them by name and value.
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return strings.Compare(escape(a), escape(b))
+	})
+
+	parameters := make([]string, 0, len(query))
+	for _, name := range names {
+		values := make([]string, len(query[name]))
+		for i, value := range query[name] {
+			values[i] = escape(value)
+		}
+		slices.Sort(values)
+		for _, value := range values {
+			parameters = append(parameters, escape(name)+"="+value)
+		}
+	}
+
+	return strings.Join(parameters, "&")
+}
+
+// canonicalHeaders returns the names of the signed headers and their canonical form, the host and every header
+// except the ignored ones are signed.
+func canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string][]string{"host": {host}}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "host" || slices.Contains(ignoredHeaders, name) {
+			continue
+		}
+		headers[name] = append(headers[name], values...)
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		values := make([]string, len(headers[name]))
+		for i, value := range headers[name] {
+			values[i] = strings.Join(strings.Fields(value), " ")
+		}
+		canonical.WriteString(name + ":" + strings.Join(values, ",") + "\n")
+	}
+
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// escape percent-encodes every byte except the unreserved characters, RFC 3986 section 2.3.
+func escape(s string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+
+	return escaped.String()
+}
+
+func hashHex(data []byte) string {
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
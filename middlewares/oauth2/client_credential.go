@@ -5,23 +5,31 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/denizgursoy/inpu"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
+// tokenExpiryDelta refreshes the token a bit before it expires, so it does not expire on the way to the server.
+const tokenExpiryDelta = 10 * time.Second
+
 type ClientCredentialsMiddleware struct {
-	tokenSource  oauth2.TokenSource
+	config       clientcredentials.Config
 	mu           sync.RWMutex
 	token        *oauth2.Token
 	refreshMutex sync.Mutex
-	next         http.RoundTripper
+}
+
+type clientCredentialsTransport struct {
+	*ClientCredentialsMiddleware
+	next http.RoundTripper
 }
 
 func NewClientCredentialsMiddleware(config clientcredentials.Config) inpu.Middleware {
 	return &ClientCredentialsMiddleware{
-		tokenSource: config.TokenSource(context.Background()),
+		config: config,
 	}
 }
 
@@ -34,13 +42,11 @@ func (m *ClientCredentialsMiddleware) Priority() int {
 }
 
 func (m *ClientCredentialsMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
-	m.next = next
-
-	return m
+	return &clientCredentialsTransport{ClientCredentialsMiddleware: m, next: next}
 }
 
-func (m *ClientCredentialsMiddleware) RoundTrip(request *http.Request) (*http.Response, error) {
-	token, err := m.getValidToken()
+func (m *clientCredentialsTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	token, err := m.getValidToken(inpu.ExtractClockFromContext(request.Context()).Now())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get oauth2 token: %w", err)
 	}
@@ -50,19 +56,22 @@ func (m *ClientCredentialsMiddleware) RoundTrip(request *http.Request) (*http.Re
 	return m.next.RoundTrip(request)
 }
 
-func (m *ClientCredentialsMiddleware) getValidToken() (*oauth2.Token, error) {
-	m.mu.RLock()
-	if m.token != nil && m.token.Valid() {
-		defer m.mu.RUnlock()
-
-		return m.token, nil
+// getValidToken returns the current token, or fetches a new one if it expires at now. The expiry is checked with the
+// clock of the request instead of oauth2.Token.Valid, so the refresh can be tested with a fake clock.
+func (m *ClientCredentialsMiddleware) getValidToken(now time.Time) (*oauth2.Token, error) {
+	if token := m.currentToken(now); token != nil {
+		return token, nil
 	}
-	m.mu.RUnlock()
 
 	m.refreshMutex.Lock()
 	defer m.refreshMutex.Unlock()
 
-	token, err := m.tokenSource.Token()
+	// another request may have refreshed the token while this one waited
+	if token := m.currentToken(now); token != nil {
+		return token, nil
+	}
+
+	token, err := m.config.Token(context.Background())
 	if err != nil {
 		return nil, err
 	}
@@ -73,3 +82,17 @@ func (m *ClientCredentialsMiddleware) getValidToken() (*oauth2.Token, error) {
 
 	return token, nil
 }
+
+func (m *ClientCredentialsMiddleware) currentToken(now time.Time) *oauth2.Token {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.token == nil || m.token.AccessToken == "" {
+		return nil
+	}
+	if !m.token.Expiry.IsZero() && !now.Add(tokenExpiryDelta).Before(m.token.Expiry) {
+		return nil
+	}
+
+	return m.token
+}
@@ -2,11 +2,15 @@ package oauth2
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/denizgursoy/inpu"
+	"github.com/denizgursoy/inpu/inputest"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
@@ -48,3 +52,45 @@ func TestClientCredentialsMiddleware(t *testing.T) {
 		t.Fatalf("request failed: %v", err)
 	}
 }
+
+func TestClientCredentialsMiddleware_Refreshes_Expired_Token(t *testing.T) {
+	var tokens atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", tokens.Add(1)),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer tokenServer.Close()
+
+	var received []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer apiServer.Close()
+
+	clock := inputest.NewFakeClock(time.Now())
+	client := inpu.New().
+		BasePath(apiServer.URL).
+		Clock(clock).
+		Use(NewClientCredentialsMiddleware(clientcredentials.Config{
+			ClientID:     "test-client",
+			ClientSecret: "test-secret",
+			TokenURL:     tokenServer.URL + "/token",
+		}))
+
+	for _, advance := range []time.Duration{0, 30 * time.Minute, time.Hour} {
+		clock.Advance(advance)
+		if err := client.Get("/api/data").Send(); err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+	}
+
+	expected := []string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}
+	if fmt.Sprint(received) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, received)
+	}
+}
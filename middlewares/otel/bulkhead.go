@@ -0,0 +1,56 @@
+package otel
+
+import (
+	"github.com/denizgursoy/inpu"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	attrKeyBulkheadKey      = attribute.Key("inpu.bulkhead.key")
+	attrKeyBulkheadRejected = attribute.Key("inpu.bulkhead.rejected")
+)
+
+// NewBulkheadRecorder returns a hook for inpu.Hooks.OnBulkhead that records the queue wait time of the requests
+// in inpu.bulkhead.wait.duration and the queue depth in inpu.bulkhead.queue.depth.
+// Only WithMeterProvider of the options is used.
+//
+// Usage:
+//
+//	client := inpu.New().
+//		Use(inpu.ConcurrencyLimitMiddleware(inpu.ConcurrencyLimitConfig{MaxConcurrent: 20})).
+//		Hooks(inpu.Hooks{OnBulkhead: otel.NewBulkheadRecorder()})
+func NewBulkheadRecorder(opts ...Option) func(event inpu.BulkheadEvent) {
+	cfg := config{
+		meterProvider: otel.GetMeterProvider(),
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(instrumentationName)
+	waitDuration := must(meter.Float64Histogram(
+		"inpu.bulkhead.wait.duration",
+		metric.WithDescription("Time the requests waited for a bulkhead slot"),
+		metric.WithUnit("s"),
+	))
+	queueDepth := must(meter.Int64Histogram(
+		"inpu.bulkhead.queue.depth",
+		metric.WithDescription("Number of requests waiting in the bulkhead queue when a request arrived"),
+		metric.WithUnit("{request}"),
+	))
+
+	return func(event inpu.BulkheadEvent) {
+		ctx := event.Request.Context()
+		attrs := metric.WithAttributes(
+			attrKeyBulkheadKey.String(event.Key),
+			attrKeyBulkheadRejected.Bool(event.Err != nil),
+		)
+
+		waitDuration.Record(ctx, event.Wait.Seconds(), attrs)
+		queueDepth.Record(ctx, int64(event.QueueDepth), attrs)
+	}
+}
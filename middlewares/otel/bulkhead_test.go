@@ -0,0 +1,52 @@
+package otel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denizgursoy/inpu"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestBulkheadRecorder(t *testing.T) {
+	_, metricReader, opts := setupTestProviders(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := inpu.New().
+		BasePath(server.URL).
+		Use(inpu.ConcurrencyLimitMiddleware(inpu.ConcurrencyLimitConfig{MaxConcurrent: 1})).
+		Hooks(inpu.Hooks{OnBulkhead: NewBulkheadRecorder(opts...)})
+
+	for range 2 {
+		if err := client.Get("/").Send(); err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+	}
+
+	rm := collectMetrics(t, metricReader)
+	for _, name := range []string{"inpu.bulkhead.wait.duration", "inpu.bulkhead.queue.depth"} {
+		if findMetric(rm, name) == nil {
+			t.Errorf("expected metric %q not found", name)
+		}
+	}
+
+	queueDepth := findMetric(rm, "inpu.bulkhead.queue.depth")
+	histogram, ok := queueDepth.Data.(metricdata.Histogram[int64])
+	if !ok {
+		t.Fatalf("expected Histogram[int64], got %T", queueDepth.Data)
+	}
+
+	if len(histogram.DataPoints) != 1 || histogram.DataPoints[0].Count != 2 {
+		t.Fatalf("expected 2 recordings in one data point, got %+v", histogram.DataPoints)
+	}
+
+	if !hasAttribute(histogram.DataPoints[0].Attributes, "inpu.bulkhead.key", server.Listener.Addr().String()) {
+		t.Error("missing inpu.bulkhead.key attribute")
+	}
+}
@@ -0,0 +1,50 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/denizgursoy/inpu"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	attrKeyCircuitKey  = attribute.Key("inpu.circuit_breaker.key")
+	attrKeyCircuitFrom = attribute.Key("inpu.circuit_breaker.from")
+	attrKeyCircuitTo   = attribute.Key("inpu.circuit_breaker.to")
+)
+
+// NewCircuitBreakerRecorder returns a callback for inpu.CircuitBreakerConfig.OnStateChange that counts
+// the state changes of the circuits in the inpu.circuit_breaker.state_changes metric.
+// Only WithMeterProvider of the options is used.
+//
+// Usage:
+//
+//	client := inpu.New().Use(inpu.CircuitBreakerMiddleware(inpu.CircuitBreakerConfig{
+//		OnStateChange: otel.NewCircuitBreakerRecorder(),
+//	}))
+func NewCircuitBreakerRecorder(opts ...Option) func(change inpu.CircuitStateChange) {
+	cfg := config{
+		meterProvider: otel.GetMeterProvider(),
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	stateChanges := must(cfg.meterProvider.Meter(instrumentationName).Int64Counter(
+		"inpu.circuit_breaker.state_changes",
+		metric.WithDescription("Number of circuit breaker state changes"),
+		metric.WithUnit("{change}"),
+	))
+
+	return func(change inpu.CircuitStateChange) {
+		stateChanges.Add(context.Background(), 1, metric.WithAttributes(
+			attrKeyCircuitKey.String(change.Key),
+			attrKeyCircuitFrom.String(change.From.String()),
+			attrKeyCircuitTo.String(change.To.String()),
+		))
+	}
+}
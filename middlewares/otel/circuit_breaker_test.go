@@ -0,0 +1,54 @@
+package otel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denizgursoy/inpu"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestCircuitBreakerRecorder(t *testing.T) {
+	_, metricReader, opts := setupTestProviders(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := inpu.New().BasePath(server.URL).Use(inpu.CircuitBreakerMiddleware(inpu.CircuitBreakerConfig{
+		ConsecutiveFailures: 1,
+		OnStateChange:       NewCircuitBreakerRecorder(opts...),
+	}))
+
+	if err := client.Get("/").Send(); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	rm := collectMetrics(t, metricReader)
+	stateChanges := findMetric(rm, "inpu.circuit_breaker.state_changes")
+	if stateChanges == nil {
+		t.Fatal("inpu.circuit_breaker.state_changes not found")
+	}
+
+	sumData, ok := stateChanges.Data.(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("expected Sum[int64], got %T", stateChanges.Data)
+	}
+
+	if len(sumData.DataPoints) != 1 {
+		t.Fatalf("expected 1 data point, got %d", len(sumData.DataPoints))
+	}
+
+	dp := sumData.DataPoints[0]
+	if dp.Value != 1 {
+		t.Errorf("expected 1 state change, got %d", dp.Value)
+	}
+
+	if !hasAttribute(dp.Attributes, "inpu.circuit_breaker.from", "closed") ||
+		!hasAttribute(dp.Attributes, "inpu.circuit_breaker.to", "open") {
+		t.Error("missing from=closed and to=open attributes")
+	}
+}
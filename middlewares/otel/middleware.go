@@ -21,12 +21,12 @@ const (
 	instrumentationName = "github.com/denizgursoy/inpu/middlewares/otel"
 
 	// Custom attribute keys
-	attrKeyRequestID   = attribute.Key("inpu.request.id")
-	attrKeyResendCount = attribute.Key("http.resend_count")
+	attrKeyRequestID    = attribute.Key("inpu.request.id")
+	attrKeyResendCount  = attribute.Key("http.resend_count")
+	attrKeyHedgeAttempt = attribute.Key("inpu.hedge.attempt")
 )
 
 type otelMiddleware struct {
-	next       http.RoundTripper
 	tracer     trace.Tracer
 	propagator propagation.TextMapPropagator
 	cfg        config
@@ -40,6 +40,11 @@ type otelMiddleware struct {
 	retryTotal       metric.Int64Counter
 }
 
+type otelTransport struct {
+	*otelMiddleware
+	next http.RoundTripper
+}
+
 // NewMiddleware creates an OTel observability middleware that collects metrics and traces
 // for outgoing HTTP requests. It implements the inpu.Middleware interface.
 //
@@ -117,12 +122,10 @@ func (m *otelMiddleware) Priority() int {
 }
 
 func (m *otelMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
-	m.next = next
-
-	return m
+	return &otelTransport{otelMiddleware: m, next: next}
 }
 
-func (m *otelMiddleware) RoundTrip(req *http.Request) (*http.Response, error) {
+func (m *otelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
 
 	// Build common attributes
@@ -136,6 +139,11 @@ func (m *otelMiddleware) RoundTrip(req *http.Request) (*http.Response, error) {
 	// Extract retry attempt
 	resendCount := inpu.ExtractRetryAttemptFromContext(ctx)
 
+	// Hedge attempts are told apart from the first attempt
+	if hedge := inpu.ExtractHedgeAttemptFromContext(ctx); hedge > 0 {
+		baseAttrs = append(baseAttrs, attrKeyHedgeAttempt.Int(hedge))
+	}
+
 	// Start span if tracing is enabled
 	if m.cfg.tracingEnabled {
 		spanName := fmt.Sprintf("%s %s", req.Method, req.URL.Hostname())
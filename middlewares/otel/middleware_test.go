@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/denizgursoy/inpu"
 
@@ -614,3 +616,41 @@ func TestMiddleware_IDAndPriority(t *testing.T) {
 		t.Errorf("expected Priority 2, got %d", mw.Priority())
 	}
 }
+
+func TestMiddleware_HedgeAttemptAttribute(t *testing.T) {
+	spanExporter, _, opts := setupTestProviders(t)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// the first attempt is slow, the hedge wins
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mw := NewMiddleware(opts...)
+	client := inpu.New().BasePath(server.URL).Use(mw, inpu.HedgingMiddleware(20*time.Millisecond, 1))
+
+	err := client.Get("/hedged").Send()
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	found := false
+	for _, span := range spanExporter.GetSpans() {
+		for _, attr := range span.Attributes {
+			if attr.Key == attrKeyHedgeAttempt && attr.Value.AsInt64() == 1 {
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		t.Error("expected a span with inpu.hedge.attempt=1")
+	}
+}
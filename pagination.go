@@ -0,0 +1,547 @@
+package inpu
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	netUrl "net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrInvalidPage = errors.New("could not read the page")
+
+// Page is a fetched page of a paginated collection. It is passed to the PaginationStrategy
+// to decide the request of the next page.
+type Page struct {
+	// Index is the zero-based index of the page
+	Index int
+	// Request is the request the page is fetched with
+	Request *http.Request
+	// Response is the response of the page. Its body is already consumed, use Body instead.
+	Response *http.Response
+	// Body is the raw body of the page
+	Body []byte
+	// ItemCount is the number of items on the page
+	ItemCount int
+	// FetchedItems is the number of items on this page and all the pages before it
+	FetchedItems int
+}
+
+// PaginationStrategy decides how the next page of a collection is requested.
+type PaginationStrategy interface {
+	// NextRequest returns the request of the page after the provided one.
+	// It returns nil when there are no more pages.
+	NextRequest(page *Page) (*http.Request, error)
+}
+
+// IndexedPaginationStrategy is a PaginationStrategy that can build the request of any page without
+// fetching the previous one. Pages of an IndexedPaginationStrategy can be fetched concurrently
+// with WithPageConcurrency.
+type IndexedPaginationStrategy interface {
+	PaginationStrategy
+	// RequestForPage returns the request of the page with the provided index derived from the first request.
+	RequestForPage(first *http.Request, index int) *http.Request
+}
+
+// PaginationOption configures Paginate.
+type PaginationOption func(*paginationConfig)
+
+type paginationConfig struct {
+	itemsField  string
+	maxItems    int
+	concurrency int
+}
+
+// WithItemsField sets the dot separated path of the JSON array that holds the items of a page, for example "data.items".
+// By default, the body of the page is expected to be a JSON array.
+func WithItemsField(path string) PaginationOption {
+	return func(c *paginationConfig) {
+		c.itemsField = path
+	}
+}
+
+// WithMaxItems stops the iteration after n items are yielded. Zero means no limit.
+func WithMaxItems(n int) PaginationOption {
+	return func(c *paginationConfig) {
+		c.maxItems = n
+	}
+}
+
+// WithPageConcurrency fetches up to n pages concurrently. The items are still yielded in page order.
+// It only has an effect with an IndexedPaginationStrategy, other strategies need the previous page
+// to request the next one.
+func WithPageConcurrency(n int) PaginationOption {
+	return func(c *paginationConfig) {
+		c.concurrency = n
+	}
+}
+
+// Paginate walks a paginated collection starting from firstReq and yields its items one by one.
+// Every page is sent with the client, so it goes through the middlewares of the client.
+// If a page could not be fetched or decoded, the error is yielded, and the iteration stops.
+// Pages with a status other than 2xx are yielded as DefaultError.
+// Usage:
+//
+//	for todo, err := range Paginate[Todo](client, client.Get("/todos"), LinkHeaderPagination()) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(todo.Title)
+//	}
+func Paginate[T any](client *Client, firstReq *Req, strategy PaginationStrategy, opts ...PaginationOption) iter.Seq2[T, error] {
+	cfg := paginationConfig{
+		concurrency: 1,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		if !firstReq.isSuccessfullyCreated() {
+			yield(zero, firstReq.requestCreationError)
+
+			return
+		}
+
+		if client != nil && firstReq.client != client {
+			client.prepareClientOnce()
+			firstReq.client = client
+			firstReq.userClient = client.userClient
+		}
+
+		p := &paginator[T]{
+			req: firstReq,
+			cfg: cfg,
+		}
+		p.run(firstReq.prepareRequest(), strategy, yield)
+	}
+}
+
+type paginator[T any] struct {
+	req     *Req
+	cfg     paginationConfig
+	fetched int
+	yielded int
+}
+
+func (p *paginator[T]) run(first *http.Request, strategy PaginationStrategy, yield func(T, error) bool) {
+	var zero T
+	indexed, isIndexed := strategy.(IndexedPaginationStrategy)
+	next := first
+
+	for index := 0; next != nil; {
+		var pages []pageResult[T]
+		if isIndexed && p.cfg.concurrency > 1 && index > 0 {
+			pages = p.fetchConcurrently(first, indexed, index)
+		} else {
+			items, page, err := p.fetch(next, index)
+			pages = []pageResult[T]{{items: items, page: page, err: err}}
+		}
+
+		for i := range pages {
+			if pages[i].err != nil {
+				yield(zero, pages[i].err)
+
+				return
+			}
+
+			if !p.yieldItems(pages[i].items, yield) {
+				return
+			}
+
+			page := pages[i].page
+			p.fetched += page.ItemCount
+			page.FetchedItems = p.fetched
+
+			var err error
+			next, err = strategy.NextRequest(page)
+			if err != nil {
+				yield(zero, err)
+
+				return
+			}
+			index++
+
+			if next == nil {
+				return
+			}
+		}
+	}
+}
+
+type pageResult[T any] struct {
+	items []T
+	page  *Page
+	err   error
+}
+
+func (p *paginator[T]) fetchConcurrently(first *http.Request, strategy IndexedPaginationStrategy, from int) []pageResult[T] {
+	results := make([]pageResult[T], p.cfg.concurrency)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			items, page, err := p.fetch(strategy.RequestForPage(first, from+i), from+i)
+			results[i] = pageResult[T]{items: items, page: page, err: err}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (p *paginator[T]) yieldItems(items []T, yield func(T, error) bool) bool {
+	for i := range items {
+		if p.cfg.maxItems > 0 && p.yielded >= p.cfg.maxItems {
+			return false
+		}
+
+		p.yielded++
+		if !yield(items[i], nil) {
+			return false
+		}
+	}
+
+	return p.cfg.maxItems <= 0 || p.yielded < p.cfg.maxItems
+}
+
+func (p *paginator[T]) fetch(req *http.Request, index int) ([]T, *Page, error) {
+	client, err := p.req.httpClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	httpResponse, cancel, err := p.req.do(client, req)
+	defer cancel()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer DrainBodyAndClose(httpResponse)
+
+	if !StatusIsSuccess.Match(httpResponse.StatusCode) {
+		return nil, nil, &DefaultError{res: httpResponse}
+	}
+
+	body, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidPage, err)
+	}
+
+	items, err := decodePageItems[T](body, p.cfg.itemsField)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidPage, err)
+	}
+
+	return items, &Page{
+		Index:     index,
+		Request:   req,
+		Response:  httpResponse,
+		Body:      body,
+		ItemCount: len(items),
+	}, nil
+}
+
+func decodePageItems[T any](body []byte, itemsField string) ([]T, error) {
+	var items []T
+	if len(strings.TrimSpace(itemsField)) == 0 {
+		if err := jsonUnmarshalFromReader(bytes.NewReader(body), &items); err != nil {
+			return nil, err
+		}
+
+		return items, nil
+	}
+
+	value, err := jsonFieldByPath(body, itemsField)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	// the raw items are decoded into T directly, so the large numbers are not rounded to a float64
+	if err := jsonUnmarshalFromReader(bytes.NewReader(value), &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// jsonFieldByPath returns the raw value at the dot separated path of the JSON object. It returns nil if the field
+// does not exist or is null.
+func jsonFieldByPath(body []byte, path string) (jsonRawValue, error) {
+	var value jsonRawValue
+	if err := jsonUnmarshalFromReader(bytes.NewReader(body), &value); err != nil {
+		return nil, err
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		if !bytes.HasPrefix(bytes.TrimSpace(value), []byte("{")) {
+			return nil, nil
+		}
+
+		var object map[string]jsonRawValue
+		if err := jsonUnmarshalFromReader(bytes.NewReader(value), &object); err != nil {
+			return nil, err
+		}
+		value = object[key]
+	}
+
+	if string(bytes.TrimSpace(value)) == "null" {
+		return nil, nil
+	}
+
+	return value, nil
+}
+
+// LinkHeaderPagination follows the RFC 8288 Link header of the response, for example:
+// Link: <https://api.example.com/items?page=2>; rel="next"
+// The iteration stops when the response does not have a link with the relation type "next".
+func LinkHeaderPagination() PaginationStrategy {
+	return linkHeaderPagination{}
+}
+
+type linkHeaderPagination struct{}
+
+func (linkHeaderPagination) NextRequest(page *Page) (*http.Request, error) {
+	next := parseNextLink(page.Response.Header.Values(HeaderLink))
+	if next == "" {
+		return nil, nil
+	}
+
+	ref, err := netUrl.Parse(next)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCouldNotParsePath, err)
+	}
+
+	return cloneRequestWithURL(page.Request, page.Request.URL.ResolveReference(ref)), nil
+}
+
+// parseNextLink returns the target of the first link with the relation type "next", RFC 8288 section 3. The target
+// is read between < and > first, so a comma or a semicolon in its query does not split it.
+func parseNextLink(headers []string) string {
+	for _, header := range headers {
+		rest := header
+		for {
+			start := strings.IndexByte(rest, '<')
+			if start < 0 {
+				break
+			}
+			end := strings.IndexByte(rest[start:], '>')
+			if end < 0 {
+				break
+			}
+			target := rest[start+1 : start+end]
+			rest = rest[start+end+1:]
+
+			// the parameters of the link end at the next link-value
+			params := rest
+			if next := strings.IndexByte(rest, '<'); next >= 0 {
+				params = rest[:next]
+			}
+			if linkHasRel(params, "next") {
+				return target
+			}
+		}
+	}
+
+	return ""
+}
+
+// linkHasRel reports whether the parameters of a link-value, like `; rel="next last"`, contain the relation type.
+func linkHasRel(params, relationType string) bool {
+	for _, param := range strings.Split(params, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		// a quoted value can contain a comma, an unquoted one ends at the separator of the next link-value
+		if strings.HasPrefix(value, `"`) {
+			value, _, _ = strings.Cut(value[1:], `"`)
+		} else {
+			value, _, _ = strings.Cut(value, ",")
+		}
+		for _, rel := range strings.Fields(value) {
+			if strings.EqualFold(rel, relationType) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// CursorPagination reads the cursor of the next page from the dot separated cursorField of the JSON body
+// and sends it in the cursorParam query parameter. The iteration stops when the cursor is empty or missing.
+// Usage:
+//
+//	CursorPagination("meta.next_cursor", "cursor")
+func CursorPagination(cursorField, cursorParam string) PaginationStrategy {
+	return cursorPagination{
+		cursorField: cursorField,
+		cursorParam: cursorParam,
+	}
+}
+
+type cursorPagination struct {
+	cursorField string
+	cursorParam string
+}
+
+func (c cursorPagination) NextRequest(page *Page) (*http.Request, error) {
+	value, err := jsonFieldByPath(page.Body, c.cursorField)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPage, err)
+	}
+
+	// a numeric cursor is sent as it is written, a float64 would round the large ones
+	cursor := string(bytes.TrimSpace(value))
+	if strings.HasPrefix(cursor, `"`) {
+		if err := jsonUnmarshalFromReader(bytes.NewReader(value), &cursor); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPage, err)
+		}
+	}
+
+	if cursor == "" {
+		return nil, nil
+	}
+
+	return withQueryValue(page.Request, c.cursorParam, cursor), nil
+}
+
+// PageNumberPagination sends the page number in the pageParam query parameter starting from firstPage.
+// The iteration stops on the first empty page.
+// Usage:
+//
+//	PageNumberPagination("page", 1)
+func PageNumberPagination(pageParam string, firstPage int) IndexedPaginationStrategy {
+	return pageNumberPagination{
+		pageParam: pageParam,
+		firstPage: firstPage,
+	}
+}
+
+type pageNumberPagination struct {
+	pageParam string
+	firstPage int
+}
+
+func (p pageNumberPagination) NextRequest(page *Page) (*http.Request, error) {
+	if page.ItemCount == 0 {
+		return nil, nil
+	}
+
+	return p.RequestForPage(page.Request, page.Index+1), nil
+}
+
+func (p pageNumberPagination) RequestForPage(first *http.Request, index int) *http.Request {
+	return withQueryValue(first, p.pageParam, strconv.Itoa(p.firstPage+index))
+}
+
+// OffsetPagination sends the offset of the first item in the offsetParam and the page size in the limitParam query parameters.
+// The iteration stops when a page has fewer items than the limit.
+// Usage:
+//
+//	OffsetPagination("offset", "limit", 100)
+func OffsetPagination(offsetParam, limitParam string, limit int) IndexedPaginationStrategy {
+	return offsetPagination{
+		offsetParam: offsetParam,
+		limitParam:  limitParam,
+		limit:       limit,
+	}
+}
+
+type offsetPagination struct {
+	offsetParam string
+	limitParam  string
+	limit       int
+}
+
+func (o offsetPagination) NextRequest(page *Page) (*http.Request, error) {
+	if page.ItemCount < o.limit || page.ItemCount == 0 {
+		return nil, nil
+	}
+
+	return o.RequestForPage(page.Request, page.Index+1), nil
+}
+
+func (o offsetPagination) RequestForPage(first *http.Request, index int) *http.Request {
+	req := withQueryValue(first, o.offsetParam, strconv.Itoa(index*o.limit))
+
+	return withQueryValue(req, o.limitParam, strconv.Itoa(o.limit))
+}
+
+// TotalCountPagination sends the page number in the pageParam query parameter starting from firstPage
+// and reads the total number of items from the X-Total-Count header.
+// The iteration stops when all the items are fetched or a page is empty.
+// Usage:
+//
+//	TotalCountPagination("page", 1)
+func TotalCountPagination(pageParam string, firstPage int) IndexedPaginationStrategy {
+	return totalCountPagination{
+		pages: pageNumberPagination{
+			pageParam: pageParam,
+			firstPage: firstPage,
+		},
+	}
+}
+
+type totalCountPagination struct {
+	pages pageNumberPagination
+}
+
+func (t totalCountPagination) NextRequest(page *Page) (*http.Request, error) {
+	if page.ItemCount == 0 {
+		return nil, nil
+	}
+
+	header := page.Response.Header.Get(HeaderXTotalCount)
+	if header != "" {
+		total, err := strconv.Atoi(header)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s header: %w", ErrInvalidPage, HeaderXTotalCount, err)
+		}
+		if page.FetchedItems >= total {
+			return nil, nil
+		}
+	}
+
+	return t.pages.RequestForPage(page.Request, page.Index+1), nil
+}
+
+func (t totalCountPagination) RequestForPage(first *http.Request, index int) *http.Request {
+	return t.pages.RequestForPage(first, index)
+}
+
+func withQueryValue(req *http.Request, key, value string) *http.Request {
+	url := *req.URL
+	queries := url.Query()
+	queries.Set(key, value)
+	url.RawQuery = queries.Encode()
+
+	return cloneRequestWithURL(req, &url)
+}
+
+func cloneRequestWithURL(req *http.Request, url *netUrl.URL) *http.Request {
+	clonedReq := req.Clone(req.Context())
+	clonedReq.URL = url
+	clonedReq.Host = ""
+
+	if req.GetBody != nil {
+		body, _ := req.GetBody()
+		clonedReq.Body = body
+	}
+
+	return clonedReq
+}
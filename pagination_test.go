@@ -0,0 +1,233 @@
+package inpu
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+)
+
+type paginatedItem struct {
+	ID int `json:"id"`
+}
+
+func writePage(w http.ResponseWriter, from, to int) {
+	w.Header().Set(HeaderContentType, MimeTypeJson)
+	items := make([]paginatedItem, 0)
+	for i := from; i < to; i++ {
+		items = append(items, paginatedItem{ID: i})
+	}
+	data, _ := jsonMarshal(items)
+	w.Write(data)
+}
+
+func collectItems(seq func(yield func(paginatedItem, error) bool)) ([]int, error) {
+	ids := make([]int, 0)
+	for item, err := range seq {
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, item.ID)
+	}
+
+	return ids, nil
+}
+
+func (c *ClientSuite) Test_Paginate_LinkHeader() {
+	c.T().Parallel()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 2 {
+			w.Header().Add(HeaderLink, fmt.Sprintf(`<%s/items?page=%d>; rel="next", <%s/items?page=2>; rel="last"`,
+				server.URL, page+1, server.URL))
+		}
+		writePage(w, page*2, page*2+2)
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL)
+	ids, err := collectItems(Paginate[paginatedItem](client, client.Get("/items"), LinkHeaderPagination()))
+
+	c.Require().NoError(err)
+	c.Require().Equal([]int{0, 1, 2, 3, 4, 5}, ids)
+}
+
+func (c *ClientSuite) Test_Paginate_Cursor() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Write([]byte(`{"data":[{"id":1},{"id":2}],"meta":{"next":"abc"}}`))
+		case "abc":
+			w.Write([]byte(`{"data":[{"id":3}],"meta":{"next":null}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL)
+	ids, err := collectItems(Paginate[paginatedItem](client, client.Get("/items"),
+		CursorPagination("meta.next", "cursor"), WithItemsField("data")))
+
+	c.Require().NoError(err)
+	c.Require().Equal([]int{1, 2, 3}, ids)
+}
+
+func (c *ClientSuite) Test_Paginate_LinkHeader_With_Comma_And_Semicolon_In_Query() {
+	c.T().Parallel()
+	queries := make(chan string, 2)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.RawQuery
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Add(HeaderLink, fmt.Sprintf(`<%s/items?sort=a,b&filter=x;y&page=2>; rel="next", `+
+				`<%s/items?sort=a,b&page=9>; rel=last`, server.URL, server.URL))
+			writePage(w, 0, 2)
+
+			return
+		}
+		writePage(w, 2, 3)
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL)
+	ids, err := collectItems(Paginate[paginatedItem](client, client.Get("/items"), LinkHeaderPagination()))
+
+	c.Require().NoError(err)
+	c.Require().Equal([]int{0, 1, 2}, ids)
+	c.Require().Equal("", <-queries)
+	c.Require().Equal("sort=a,b&filter=x;y&page=2", <-queries)
+}
+
+func (c *ClientSuite) Test_Paginate_Keeps_Large_Numbers() {
+	c.T().Parallel()
+	cursors := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		cursors <- cursor
+		if cursor == "" {
+			w.Write([]byte(`{"data":[{"id":9007199254740993}],"meta":{"next":9007199254740995}}`))
+
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":9007199254740997}],"meta":{}}`))
+	}))
+	defer server.Close()
+
+	type bigItem struct {
+		ID int64 `json:"id"`
+	}
+	client := New().BasePath(server.URL)
+	ids := make([]int64, 0)
+	for item, err := range Paginate[bigItem](client, client.Get("/items"),
+		CursorPagination("meta.next", "cursor"), WithItemsField("data")) {
+		c.Require().NoError(err)
+		ids = append(ids, item.ID)
+	}
+
+	c.Require().Equal([]int64{9007199254740993, 9007199254740997}, ids)
+	c.Require().Equal("", <-cursors)
+	c.Require().Equal("9007199254740995", <-cursors)
+}
+
+func (c *ClientSuite) Test_Paginate_PageNumber_With_Concurrency() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil {
+			page = 1
+		}
+		if page > 5 {
+			writePage(w, 0, 0)
+
+			return
+		}
+		writePage(w, page*10, page*10+2)
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL)
+	ids, err := collectItems(Paginate[paginatedItem](client, client.Get("/items"),
+		PageNumberPagination("page", 1), WithPageConcurrency(3)))
+
+	c.Require().NoError(err)
+	c.Require().Equal([]int{10, 11, 20, 21, 30, 31, 40, 41, 50, 51}, ids)
+	c.Require().GreaterOrEqual(calls.Load(), int32(6))
+}
+
+func (c *ClientSuite) Test_Paginate_Offset() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit == 0 {
+			limit = 2
+		}
+		writePage(w, offset, min(offset+limit, 5))
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL)
+	ids, err := collectItems(Paginate[paginatedItem](client, client.Get("/items").QueryInt("limit", 2),
+		OffsetPagination("offset", "limit", 2)))
+
+	c.Require().NoError(err)
+	c.Require().Equal([]int{0, 1, 2, 3, 4}, ids)
+}
+
+func (c *ClientSuite) Test_Paginate_TotalCount_And_MaxItems() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		w.Header().Set(HeaderXTotalCount, "6")
+		writePage(w, page*3, page*3+3)
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL)
+	ids, err := collectItems(Paginate[paginatedItem](client, client.Get("/items"), TotalCountPagination("page", 0)))
+	c.Require().NoError(err)
+	c.Require().Equal([]int{0, 1, 2, 3, 4, 5}, ids)
+	c.Require().EqualValues(2, calls.Load())
+
+	ids, err = collectItems(Paginate[paginatedItem](client, client.Get("/items"), TotalCountPagination("page", 0),
+		WithMaxItems(4)))
+	c.Require().NoError(err)
+	c.Require().Equal([]int{0, 1, 2, 3}, ids)
+}
+
+func (c *ClientSuite) Test_Paginate_Goes_Through_Middlewares_And_Stops_On_Error() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Require().NotEmpty(r.Header.Get(HeaderXRequestID))
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+		writePage(w, 0, 1)
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(RequestIDMiddleware())
+	ids, err := collectItems(Paginate[paginatedItem](client, client.Get("/items"), PageNumberPagination("page", 1)))
+
+	var defaultError *DefaultError
+	c.Require().ErrorAs(err, &defaultError)
+	c.Require().Equal([]int{0}, ids)
+}
+
+func (c *ClientSuite) Test_ParseNextLink() {
+	c.T().Parallel()
+	c.Require().Equal("/items?page=3", parseNextLink([]string{`</items?page=1>; rel="prev", </items?page=3>; rel="next"`}))
+	c.Require().Equal("/b", parseNextLink([]string{`</a>; rel="last"`, `</b>; rel="next last"`}))
+	c.Require().Empty(parseNextLink([]string{`</a>; rel="prev"`}))
+	c.Require().Empty(parseNextLink(nil))
+}
@@ -0,0 +1,225 @@
+package inpu
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// the reset headers with a bigger value are a unix timestamp, not a number of seconds
+const unixTimestampThreshold = 1_000_000_000
+
+type RateLimitConfig struct {
+	// Requests is the number of requests allowed in Per. Zero means there is no client-side limit, and only the
+	// rate limit headers of the responses are followed.
+	Requests int
+	// Per is the period of Requests. Default is 1 second.
+	Per time.Duration
+	// Burst is the number of requests that can be sent at once. Default is Requests.
+	Burst int
+	// KeyFunc returns the key of the bucket a request belongs to. By default, all the requests share one bucket.
+	// Use KeyByHost to limit every host separately.
+	KeyFunc func(req *http.Request) string
+	// MaxWait makes a request fail with ErrRateLimited instead of waiting longer than it.
+	// Zero means waiting until the context of the request is done.
+	MaxWait time.Duration
+	// IgnoreHeaders disables following the rate limit headers of the responses.
+	IgnoreHeaders bool
+}
+
+type rateLimitMiddleware struct {
+	config  RateLimitConfig
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type rateLimitTransport struct {
+	*rateLimitMiddleware
+	next http.RoundTripper
+}
+
+type tokenBucket struct {
+	// rate is the number of tokens added per second, zero means the bucket never runs out of tokens
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// RateLimitMiddleware creates a middleware that throttles the requests with a token bucket before they are sent,
+// instead of getting 429 from the server. It also follows the RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Limit headers (and their X-RateLimit- variants) and the Retry-After header of 429 responses.
+// The waiting respects the context of the request. The buckets are shared by all the clients that use
+// the middleware, so create a middleware per client to limit the clients separately.
+// Usage:
+//
+//	client := New().Use(RateLimitMiddleware(RateLimitConfig{Requests: 10, Per: time.Second, KeyFunc: KeyByHost}))
+func RateLimitMiddleware(config RateLimitConfig) Middleware {
+	if config.Per <= 0 {
+		config.Per = time.Second
+	}
+	if config.Burst <= 0 {
+		config.Burst = config.Requests
+	}
+
+	return &rateLimitMiddleware{
+		config:  config,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// KeyByHost returns the host of the request, it can be used as the KeyFunc of the middlewares.
+func KeyByHost(req *http.Request) string {
+	return req.URL.Host
+}
+
+func (t *rateLimitMiddleware) ID() string {
+	return "rate-limit-middleware"
+}
+
+func (t *rateLimitMiddleware) Priority() int {
+	return 22
+}
+
+func (t *rateLimitMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
+	return &rateLimitTransport{rateLimitMiddleware: t, next: next}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := ""
+	if t.config.KeyFunc != nil {
+		key = t.config.KeyFunc(req)
+	}
+
+	clock := ExtractClockFromContext(req.Context())
+	t.mu.Lock()
+	bucket := t.bucket(key, clock.Now())
+	wait := bucket.reserve(clock.Now())
+	t.mu.Unlock()
+
+	if wait > 0 {
+		if t.config.MaxWait > 0 && wait > t.config.MaxWait {
+			t.cancel(bucket)
+
+			return nil, fmt.Errorf("%w: the request would wait %v", ErrRateLimited, wait)
+		}
+
+		ExtractLoggerFromContext(req.Context()).Debug(req.Context(), "[RATE LIMIT] Waiting %v for %s %s",
+			wait, req.Method, req.URL.Redacted())
+
+		select {
+		case <-req.Context().Done():
+			t.cancel(bucket)
+
+			return nil, req.Context().Err()
+		case <-clock.After(wait):
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err == nil && !t.config.IgnoreHeaders {
+		t.mu.Lock()
+		bucket.follow(resp, clock.Now())
+		t.mu.Unlock()
+	}
+
+	return resp, err
+}
+
+func (t *rateLimitMiddleware) bucket(key string, now time.Time) *tokenBucket {
+	bucket, ok := t.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{
+			rate:   float64(t.config.Requests) / t.config.Per.Seconds(),
+			burst:  float64(t.config.Burst),
+			tokens: float64(t.config.Burst),
+			last:   now,
+		}
+		t.buckets[key] = bucket
+	}
+
+	return bucket
+}
+
+func (t *rateLimitMiddleware) cancel(bucket *tokenBucket) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if bucket.rate > 0 {
+		bucket.tokens++
+	}
+}
+
+// reserve takes a token and returns how long to wait until the token is available.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	wait := time.Duration(0)
+	if b.rate > 0 {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		b.tokens--
+		if b.tokens < 0 {
+			wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+		}
+	}
+
+	if blocked := b.blockedUntil.Sub(now); blocked > wait {
+		wait = blocked
+	}
+
+	return wait
+}
+
+// follow adapts the bucket to the rate limit headers of the response.
+func (b *tokenBucket) follow(resp *http.Response, now time.Time) {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if retryAfter, ok := parseRetryAfterHeader(resp.Header.Get(HeaderRetryAfter), now); ok {
+			b.block(now.Add(retryAfter))
+		}
+	}
+
+	if limit, ok := rateLimitHeader(resp.Header, HeaderRateLimitLimit, HeaderXRateLimitLimit); ok && b.rate > 0 {
+		b.burst = min(b.burst, float64(limit))
+	}
+
+	remaining, ok := rateLimitHeader(resp.Header, HeaderRateLimitRemaining, HeaderXRateLimitRemaining)
+	if !ok {
+		return
+	}
+
+	if remaining > 0 {
+		if b.rate > 0 {
+			b.tokens = min(b.tokens, float64(remaining))
+		}
+
+		return
+	}
+
+	if reset, ok := rateLimitHeader(resp.Header, HeaderRateLimitReset, HeaderXRateLimitReset); ok {
+		if reset > unixTimestampThreshold {
+			b.block(time.Unix(reset, 0))
+		} else {
+			b.block(now.Add(time.Duration(reset) * time.Second))
+		}
+	}
+}
+
+func (b *tokenBucket) block(until time.Time) {
+	if until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+// rateLimitHeader returns the value of the first header that is a non-negative integer.
+func rateLimitHeader(header http.Header, keys ...string) (int64, bool) {
+	for _, key := range keys {
+		value, err := strconv.ParseInt(header.Get(key), 10, 64)
+		if err == nil && value >= 0 {
+			return value, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,133 @@
+package inpu
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+func (c *ClientSuite) Test_RateLimit_Throttles_Requests() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := New().
+		BasePath(server.URL).
+		Use(RateLimitMiddleware(RateLimitConfig{Requests: 20, Per: time.Second, Burst: 2}))
+
+	start := time.Now()
+	for range 4 {
+		c.Require().NoError(client.Get("/").Send())
+	}
+
+	c.Require().GreaterOrEqual(time.Since(start), 90*time.Millisecond)
+}
+
+func (c *ClientSuite) Test_RateLimit_Per_Host() {
+	c.T().Parallel()
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer second.Close()
+
+	client := New().Use(RateLimitMiddleware(RateLimitConfig{Requests: 1, Per: time.Minute, KeyFunc: KeyByHost}))
+
+	start := time.Now()
+	c.Require().NoError(client.Get(first.URL).Send())
+	c.Require().NoError(client.Get(second.URL).Send())
+	c.Require().Less(time.Since(start), time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c.Require().ErrorIs(client.GetCtx(ctx, first.URL).Send(), context.DeadlineExceeded)
+}
+
+func (c *ClientSuite) Test_RateLimit_MaxWait_Is_Not_Retried() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := New().
+		BasePath(server.URL).
+		Use(
+			RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond}),
+			RateLimitMiddleware(RateLimitConfig{Requests: 1, Per: time.Minute, MaxWait: 10 * time.Millisecond}),
+		).
+		Get("/").
+		Send()
+
+	c.Require().ErrorIs(err, ErrRateLimited)
+	c.Require().EqualValues(1, calls.Load())
+}
+
+func (c *ClientSuite) Test_RateLimit_Retries_Consume_Tokens() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	err := New().
+		BasePath(server.URL).
+		Use(
+			RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond}),
+			RateLimitMiddleware(RateLimitConfig{Requests: 1, Per: 50 * time.Millisecond}),
+		).
+		Get("/").
+		Send()
+
+	c.Require().NoError(err)
+	c.Require().EqualValues(3, calls.Load())
+	c.Require().GreaterOrEqual(time.Since(start), 90*time.Millisecond)
+}
+
+func (c *ClientSuite) Test_TokenBucket_Follows_Headers() {
+	c.T().Parallel()
+	now := time.Now()
+	newResponse := func(status int, headers map[string]string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: make(http.Header)}
+		for key, value := range headers {
+			resp.Header.Set(key, value)
+		}
+
+		return resp
+	}
+
+	bucket := &tokenBucket{rate: 10, burst: 10, tokens: 10, last: now}
+	bucket.follow(newResponse(http.StatusOK, map[string]string{
+		HeaderRateLimitLimit:     "5",
+		HeaderRateLimitRemaining: "1",
+	}), now)
+	c.Require().EqualValues(5, bucket.burst)
+	c.Require().EqualValues(1, bucket.tokens)
+	c.Require().Zero(bucket.reserve(now))
+	c.Require().Equal(100*time.Millisecond, bucket.reserve(now))
+
+	bucket = &tokenBucket{last: now}
+	bucket.follow(newResponse(http.StatusOK, map[string]string{
+		HeaderXRateLimitRemaining: "0",
+		HeaderXRateLimitReset:     strconv.FormatInt(now.Add(time.Minute).Unix(), 10),
+	}), now)
+	c.Require().InDelta(time.Minute, bucket.reserve(now), float64(time.Second))
+
+	bucket = &tokenBucket{last: now}
+	bucket.follow(newResponse(http.StatusOK, map[string]string{
+		HeaderRateLimitRemaining: "0",
+		HeaderRateLimitReset:     "30",
+	}), now)
+	c.Require().Equal(30*time.Second, bucket.reserve(now))
+
+	bucket = &tokenBucket{last: now}
+	bucket.follow(newResponse(http.StatusTooManyRequests, map[string]string{HeaderRetryAfter: "120"}), now)
+	c.Require().Equal(2*time.Minute, bucket.reserve(now))
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	netUrl "net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -24,6 +25,10 @@ type Req struct {
 	timeOut              time.Duration
 	replies              []replyBehavior
 	queries              netUrl.Values
+	client               *Client
+	isMatchInOrder       bool
+	mws                  []Middleware
+	sentAt               time.Time
 }
 
 func Get(url string) *Req {
@@ -431,7 +436,7 @@ func (r *Req) TimeOutIn(duration time.Duration) *Req {
 // Current status matcher priorities are:
 // StatusIs -> 1
 // StatusIsOneOf -> 2
-// StatusIsInformational, StatusIsSuccess, StatusIsRedirection, StatusIsClientError, StatusIsServerError -> 3
+// StatusIsInformational, StatusIsSuccess, StatusIsRedirection, StatusIsClientError, StatusIsServerError, StatusBetween -> 3
 // StatusAnyExcept -> 8
 // StatusAnyExceptOneOf -> 9
 // StatusAny -> 10
@@ -450,6 +455,7 @@ func (r *Req) TimeOutIn(duration time.Duration) *Req {
 // On(StatusIs(http.StatusOK), ThenReturnError(errors.New("something happened again"))) // second one
 //
 // It will return errors.New("something happened")
+// Call MatchInOrder to ignore the priorities and check the matchers in the order they are added.
 func (r *Req) On(statusMatcher StatusMatcher, responseHandler ResponseHandler) *Req {
 	r.replies = append(r.replies, replyBehavior{
 		statusMatcher:   statusMatcher,
@@ -459,43 +465,181 @@ func (r *Req) On(statusMatcher StatusMatcher, responseHandler ResponseHandler) *
 	return r
 }
 
+// MatchInOrder disables the priority based sorting of the status matchers.
+// The matchers are checked in the order they are added and the first match wins.
+// Request level matchers are still checked before the client level ones.
+// Usage:
+//
+// MatchInOrder().
+// OnAny(ThenReturnDefaultError). // matches every status, so the next one is never executed
+// OnOk(ThenUnmarshalJsonTo(&items))
+func (r *Req) MatchInOrder() *Req {
+	r.isMatchInOrder = true
+
+	return r
+}
+
+// Use adds middlewares only to this request. They are layered on top of the middlewares of the client,
+// and a request middleware replaces the client middleware with the same ID.
+// The middlewares are ordered by priority like the ones of the client.
+// Usage:
+//
+//	client.Post("/batch", BodyJson(items)).
+//	Use(RetryMiddleware(5)).
+//	OnOk(ThenDoNothing).
+//	Send()
+func (r *Req) Use(mws ...Middleware) *Req {
+	r.mws = addMiddlewares(r.mws, mws...)
+
+	return r
+}
+
 func (r *Req) Send() error {
 	if !r.isSuccessfullyCreated() {
 		return r.requestCreationError
 	}
 
+	client, err := r.httpClient()
+	if err != nil {
+		return err
+	}
+
+	r.sentAt = time.Now()
+	httpResponse, cancel, err := r.do(client, r.prepareRequest())
+	defer cancel()
+	if err != nil {
+		return err
+	}
+
+	defer DrainBodyAndClose(httpResponse)
+
+	return r.handleResponse(httpResponse)
+}
+
+// prepareRequest encodes the queries into the URL of the underlying request.
+func (r *Req) prepareRequest() *http.Request {
 	r.httpReq.URL.RawQuery = r.queries.Encode()
 
+	return r.httpReq
+}
+
+func (r *Req) httpClient() (*http.Client, error) {
+	if r.client != nil && r.client.middlewareError != nil {
+		return nil, r.client.middlewareError
+	}
+
 	client := r.userClient
 	if client == nil {
 		client = getDefaultClient()
 	}
 
-	if r.timeOut > 0 {
-		timeoutCtx, cancel := context.WithTimeout(r.httpReq.Context(), r.timeOut)
-		defer cancel()
+	if len(r.mws) == 0 {
+		return client, nil
+	}
 
-		r.httpReq = r.httpReq.WithContext(timeoutCtx)
+	transport := client.Transport
+	mws := slices.Clone(r.mws)
+	if r.client != nil {
+		transport = r.client.transport
+		mws = addMiddlewares(slices.Clone(r.client.preparedMws), r.mws...)
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
 	}
 
-	httpResponse, err := client.Do(r.httpReq)
+	// the client is copied, so the transport of the shared client is not modified
+	chain, err := chainMiddlewares(transport, mws)
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrConnectionFailed, err)
+		return nil, err
 	}
 
-	defer DrainBodyAndClose(httpResponse)
+	requestClient := *client
+	requestClient.Transport = chain
 
-	sort.SliceStable(r.replies, func(i, j int) bool {
-		return r.replies[i].statusMatcher.Priority() < r.replies[j].statusMatcher.Priority()
-	})
-	for i := range r.replies {
-		matcher := r.replies[i].statusMatcher
+	return &requestClient, nil
+}
+
+// do sends the request with the provided client and applies the request timeout.
+// The returned cancel function must be called after the response body is consumed.
+func (r *Req) do(client *http.Client, httpReq *http.Request) (*http.Response, context.CancelFunc, error) {
+	cancel := context.CancelFunc(func() {})
+	if r.client != nil && len(r.client.hooks) > 0 {
+		httpReq = httpReq.WithContext(context.WithValue(httpReq.Context(), contextKeyHooks, r.client.hooks))
+	}
+	if r.client != nil && r.client.clock != nil {
+		httpReq = httpReq.WithContext(ContextWithClock(httpReq.Context(), r.client.clock))
+	}
+	httpReq = httpReq.WithContext(contextWithAttemptCounter(httpReq.Context()))
+
+	if r.timeOut > 0 {
+		var timeoutCtx context.Context
+		timeoutCtx, cancel = context.WithTimeout(httpReq.Context(), r.timeOut)
+		httpReq = httpReq.WithContext(timeoutCtx)
+	}
+
+	httpResponse, err := client.Do(httpReq)
+	if err != nil {
+		return httpResponse, cancel, newTransportError(httpReq.Context(), httpReq.URL.Host, err)
+	}
+
+	return httpResponse, cancel, nil
+}
+
+func (r *Req) handleResponse(httpResponse *http.Response) error {
+	replies := r.collectReplies()
+	for i := range replies {
+		matcher := replies[i].statusMatcher
 		if matcher != nil {
 			if matcher.Match(httpResponse.StatusCode) {
-				return r.replies[i].responseHandler(httpResponse)
+				r.handlerMatched(httpResponse, matcher)
+
+				return replies[i].responseHandler(httpResponse)
 			}
 		}
 	}
 
+	if r.client != nil && r.client.isStrict {
+		return fmt.Errorf("%w: %w", ErrUnhandledStatus, &DefaultError{res: httpResponse})
+	}
+
 	return nil
 }
+
+func (r *Req) handlerMatched(httpResponse *http.Response, matcher StatusMatcher) {
+	if r.client == nil || len(r.client.hooks) == 0 {
+		return
+	}
+
+	httpReq := r.httpReq
+	if httpResponse.Request != nil {
+		httpReq = httpResponse.Request
+	}
+
+	r.client.hooks.handlerMatched(HandlerMatchedEvent{
+		Request:       httpReq,
+		Response:      httpResponse,
+		StatusMatcher: matcher,
+		Attempt:       ExtractRetryAttemptFromContext(httpReq.Context()),
+		Duration:      time.Since(r.sentAt),
+	})
+}
+
+// collectReplies returns the request level replies followed by the client level ones, sorted by priority.
+// The sort is stable, so a request level reply wins over a client level reply with the same priority.
+// In MatchInOrder mode the replies are returned in declaration order.
+func (r *Req) collectReplies() []replyBehavior {
+	replies := slices.Clone(r.replies)
+	if r.client != nil {
+		replies = append(replies, r.client.replies...)
+	}
+
+	if r.isMatchInOrder || (r.client != nil && r.client.isMatchInOrder) {
+		return replies
+	}
+
+	sort.SliceStable(replies, func(i, j int) bool {
+		return replies[i].statusMatcher.Priority() < replies[j].statusMatcher.Priority()
+	})
+
+	return replies
+}
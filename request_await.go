@@ -0,0 +1,184 @@
+package inpu
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	netUrl "net/url"
+	"time"
+)
+
+const defaultPollInterval = time.Second
+
+var ErrOperationNotCompleted = errors.New("operation did not complete")
+
+// AwaitOptions configures AwaitCompletion.
+type AwaitOptions struct {
+	// PollInterval is the time to wait between the polls when the response does not have a Retry-After header, or
+	// its wait is zero.
+	// Default is 1 second.
+	PollInterval time.Duration
+	// Timeout is the overall deadline of the operation including the first request and all the polls.
+	// Zero means the operation is polled until it completes or the context is cancelled.
+	Timeout time.Duration
+	// PollHeaders are the response headers that carry the URL to poll, checked in order.
+	// Default is Operation-Location, Location.
+	PollHeaders []string
+	// IsTerminal reports whether the operation reached a terminal state. The body of the response can be read,
+	// it is restored before the response handlers are called. By default, any status except 202 is terminal.
+	IsTerminal func(response *http.Response) bool
+}
+
+// AwaitCompletion sends the request and, if the server answers 202 Accepted with an Operation-Location or
+// Location header, polls that URL until the operation reaches a terminal state.
+// The wait between the polls respects the Retry-After header of the last response.
+// The terminal response is handled by the response handlers added with On, like in Send.
+// Usage:
+//
+//	AwaitCompletion(AwaitOptions{Timeout: 5 * time.Minute}).
+//	OnOk(ThenUnmarshalJsonTo(&report)) // handlers must be added before AwaitCompletion
+func (r *Req) AwaitCompletion(opts AwaitOptions) error {
+	if !r.isSuccessfullyCreated() {
+		return r.requestCreationError
+	}
+
+	opts = withDefaultAwaitOptions(opts)
+
+	ctx := r.httpReq.Context()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	client, err := r.httpClient()
+	if err != nil {
+		return err
+	}
+
+	r.sentAt = time.Now()
+	httpReq := r.prepareRequest().WithContext(ctx)
+	for {
+		httpResponse, cancel, err := r.do(client, httpReq)
+		if err != nil {
+			cancel()
+
+			// the timeout can expire while a poll request is in flight
+			if ctx.Err() != nil {
+				return fmt.Errorf("%w: %w", ErrOperationNotCompleted, err)
+			}
+
+			return err
+		}
+
+		done, pollURL, err := r.checkOperation(httpReq, httpResponse, opts)
+		if done || err != nil {
+			if err == nil {
+				err = r.handleResponse(httpResponse)
+			}
+			DrainBodyAndClose(httpResponse)
+			cancel()
+
+			return err
+		}
+
+		wait := opts.PollInterval
+		clock := ExtractClockFromContext(httpReq.Context())
+		// Retry-After: 0 or a past date would poll the server without a pause
+		if retryAfter, ok := parseRetryAfterHeader(httpResponse.Header.Get(HeaderRetryAfter), clock.Now()); ok &&
+			retryAfter > 0 {
+			wait = retryAfter
+		}
+		DrainBodyAndClose(httpResponse)
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrOperationNotCompleted, ctx.Err())
+		case <-clock.After(wait):
+		}
+
+		httpReq, err = newPollRequest(ctx, r.httpReq, pollURL)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func withDefaultAwaitOptions(opts AwaitOptions) AwaitOptions {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+
+	if len(opts.PollHeaders) == 0 {
+		opts.PollHeaders = []string{HeaderOperationLocation, HeaderLocation}
+	}
+
+	if opts.IsTerminal == nil {
+		opts.IsTerminal = func(response *http.Response) bool {
+			return response.StatusCode != http.StatusAccepted
+		}
+	}
+
+	return opts
+}
+
+// checkOperation reports whether the response is terminal, otherwise it returns the URL to poll next.
+func (r *Req) checkOperation(httpReq *http.Request, httpResponse *http.Response, opts AwaitOptions,
+) (bool, *netUrl.URL, error) {
+	// the body of the middlewares is closed here, it releases the bulkhead slot and the attempt context
+	body, err := io.ReadAll(httpResponse.Body)
+	_ = httpResponse.Body.Close()
+	if err != nil {
+		return false, nil, fmt.Errorf("%w: %w", ErrConnectionFailed, err)
+	}
+	httpResponse.Body = io.NopCloser(bytes.NewReader(body))
+
+	// IsTerminal reads a copy, the response handlers get the whole body
+	probe := *httpResponse
+	probe.Body = io.NopCloser(bytes.NewReader(body))
+	isTerminal := opts.IsTerminal(&probe)
+
+	if isTerminal {
+		return true, nil, nil
+	}
+
+	for _, header := range opts.PollHeaders {
+		location := httpResponse.Header.Get(header)
+		if location == "" {
+			continue
+		}
+
+		ref, err := netUrl.Parse(location)
+		if err != nil {
+			return false, nil, fmt.Errorf("%w: %w", ErrCouldNotParsePath, err)
+		}
+
+		return false, httpReq.URL.ResolveReference(ref), nil
+	}
+
+	// the same URL is polled if the server does not send a new location
+	if httpReq.Method == http.MethodGet {
+		return false, httpReq.URL, nil
+	}
+
+	// without a poll URL, the first response is the final one
+	return true, nil, nil
+}
+
+// newPollRequest creates a GET request to the poll URL with the headers of the original request.
+func newPollRequest(ctx context.Context, original *http.Request, pollURL *netUrl.URL) (*http.Request, error) {
+	pollReq, err := http.NewRequestWithContext(ctx, http.MethodGet, pollURL.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRequestCreationFailed, err)
+	}
+
+	pollReq.Header = original.Header.Clone()
+	pollReq.Header.Del(HeaderContentType)
+	pollReq.Header.Del(HeaderContentLength)
+
+	return pollReq, nil
+}
@@ -0,0 +1,201 @@
+package inpu
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+)
+
+func (c *ClientSuite) Test_AwaitCompletion_Polls_Operation_Location() {
+	c.T().Parallel()
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/reports":
+			c.Require().Equal(http.MethodPost, r.Method)
+			w.Header().Set(HeaderOperationLocation, "/operations/1")
+			w.Header().Set(HeaderRetryAfter, "0")
+			w.WriteHeader(http.StatusAccepted)
+		case "/operations/1":
+			c.Require().Equal(http.MethodGet, r.Method)
+			c.Require().Equal("token", r.Header.Get("X-Token"))
+			if polls.Add(1) < 3 {
+				w.Header().Set(HeaderRetryAfter, "0")
+				w.WriteHeader(http.StatusAccepted)
+
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"foo":"done"}`))
+		}
+	}))
+	defer server.Close()
+
+	result := testModel{}
+	err := New().
+		BasePath(server.URL).
+		Header("X-Token", "token").
+		Post("/reports", BodyString(`{}`)).
+		OnOk(ThenUnmarshalJsonTo(&result)).
+		OnAny(ThenReturnDefaultError).
+		AwaitCompletion(AwaitOptions{PollInterval: time.Millisecond})
+
+	c.Require().NoError(err)
+	c.Require().Equal("done", result.Foo)
+	c.Require().EqualValues(3, polls.Load())
+}
+
+func (c *ClientSuite) Test_AwaitCompletion_Custom_Terminal_Predicate() {
+	c.T().Parallel()
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jobs" {
+			w.Header().Set(HeaderLocation, "/jobs/1")
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+		if polls.Add(1) < 2 {
+			w.Write([]byte(`{"foo":"running"}`))
+
+			return
+		}
+		w.Write([]byte(`{"foo":"succeeded"}`))
+	}))
+	defer server.Close()
+
+	result := testModel{}
+	err := Post(server.URL+"/jobs", nil).
+		OnOk(ThenUnmarshalJsonTo(&result)).
+		AwaitCompletion(AwaitOptions{
+			PollInterval: time.Millisecond,
+			IsTerminal: func(response *http.Response) bool {
+				status := testModel{}
+				_ = ThenUnmarshalJsonTo(&status)(response)
+
+				return response.StatusCode != http.StatusAccepted && status.Foo != "running"
+			},
+		})
+
+	c.Require().NoError(err)
+	c.Require().Equal("succeeded", result.Foo)
+}
+
+func (c *ClientSuite) Test_AwaitCompletion_Not_Accepted_Is_Final() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	called := false
+	err := Post(server.URL, nil).
+		OnCreated(func(_ *http.Response) error {
+			called = true
+
+			return nil
+		}).
+		AwaitCompletion(AwaitOptions{})
+
+	c.Require().NoError(err)
+	c.Require().True(called)
+}
+
+func (c *ClientSuite) Test_AwaitCompletion_Zero_Retry_After_Uses_Poll_Interval() {
+	c.T().Parallel()
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/operations/1" {
+			polls.Add(1)
+		}
+		w.Header().Set(HeaderLocation, "/operations/1")
+		w.Header().Set(HeaderRetryAfter, "0")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	err := Post(server.URL, nil).
+		AwaitCompletion(AwaitOptions{
+			PollInterval: 50 * time.Millisecond,
+			Timeout:      175 * time.Millisecond,
+		})
+
+	c.Require().ErrorIs(err, ErrOperationNotCompleted)
+	c.Require().LessOrEqual(polls.Load(), int32(4))
+}
+
+func (c *ClientSuite) Test_AwaitCompletion_Timeout() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderLocation, "/operations/1")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	err := Post(server.URL, nil).
+		AwaitCompletion(AwaitOptions{
+			PollInterval: 20 * time.Millisecond,
+			Timeout:      100 * time.Millisecond,
+		})
+
+	c.Require().ErrorIs(err, ErrOperationNotCompleted)
+	c.Require().ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (c *ClientSuite) Test_AwaitCompletion_Context_Cancellation() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderLocation, "/operations/1")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	err := PostCtx(ctx, server.URL, nil).
+		AwaitCompletion(AwaitOptions{PollInterval: 10 * time.Millisecond})
+
+	c.Require().ErrorIs(err, context.Canceled)
+}
+
+func (c *ClientSuite) Test_AwaitCompletion_Releases_Bulkhead_Slot() {
+	c.T().Parallel()
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jobs" {
+			w.Header().Set(HeaderLocation, "/jobs/1")
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+		if polls.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+		w.Write([]byte(`{"foo":"done"}`))
+	}))
+	defer server.Close()
+
+	client := New().
+		BasePath(server.URL).
+		Use(ConcurrencyLimitMiddleware(ConcurrencyLimitConfig{
+			MaxConcurrent: 1,
+			QueueTimeout:  200 * time.Millisecond,
+		}))
+
+	for range 3 {
+		result := testModel{}
+		err := client.Post("/jobs", nil).
+			OnOk(ThenUnmarshalJsonTo(&result)).
+			OnAny(ThenReturnDefaultError).
+			AwaitCompletion(AwaitOptions{PollInterval: time.Millisecond})
+
+		c.Require().NoError(err)
+		c.Require().Equal("done", result.Foo)
+	}
+	c.Require().EqualValues(6, polls.Load())
+}
@@ -246,3 +246,39 @@ func (c *ClientSuite) Test_OnUnauthorized_Shorthand() {
 
 	c.Require().ErrorIs(err, expectedErr)
 }
+
+func (c *ClientSuite) Test_MatchInOrder_First_Declared_Wins() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	expectedErr := errors.New("first declared")
+	err := New().Get(server.URL).
+		MatchInOrder().
+		OnAny(ThenReturnError(expectedErr)).
+		OnOk(ThenDoNothing).
+		Send()
+
+	c.Require().ErrorIs(err, expectedErr)
+}
+
+func (c *ClientSuite) Test_Client_MatchInOrder_Checks_Request_Replies_First() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	requestErr := errors.New("request level")
+	client := New().
+		MatchInOrder().
+		OnOk(ThenDoNothing)
+
+	err := client.Get(server.URL).
+		OnAny(ThenReturnError(requestErr)).
+		Send()
+
+	c.Require().ErrorIs(err, requestErr)
+}
@@ -0,0 +1,101 @@
+package inpu
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetryBudgetRatio      = 0.1
+	defaultRetryBudgetWindow     = 10 * time.Second
+	defaultRetryBudgetMinRetries = 10
+	retryBudgetBuckets           = 10
+)
+
+type RetryBudgetConfig struct {
+	// Ratio is the number of retries allowed per request in the window. Default is 0.1, 10% of the requests.
+	Ratio float64
+	// Window is the sliding window the requests and the retries are counted in. Default is 10 seconds.
+	Window time.Duration
+	// MinRetries are the retries allowed in the window whatever the number of requests, so a client with a few
+	// requests can still retry. Default is 10, a negative value disables it.
+	MinRetries int
+}
+
+// RetryBudget limits the retries to a ratio of the requests in a sliding window, so the retries cannot multiply the
+// load of a backend during an incident. A budget can be shared by the retry middlewares of many clients.
+type RetryBudget struct {
+	config      RetryBudgetConfig
+	bucketWidth time.Duration
+	mu          sync.Mutex
+	buckets     [retryBudgetBuckets]retryBudgetBucket
+}
+
+type retryBudgetBucket struct {
+	start    time.Time
+	requests int
+	retries  int
+}
+
+// NewRetryBudget creates a retry budget to set in RetryConfig.Budget.
+// Usage:
+//
+//	budget := NewRetryBudget(RetryBudgetConfig{Ratio: 0.1, Window: 10 * time.Second})
+//	client := New().Use(RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 3, Budget: budget}))
+func NewRetryBudget(config RetryBudgetConfig) *RetryBudget {
+	if config.Ratio <= 0 {
+		config.Ratio = defaultRetryBudgetRatio
+	}
+	if config.Window <= 0 {
+		config.Window = defaultRetryBudgetWindow
+	}
+	if config.MinRetries == 0 {
+		config.MinRetries = defaultRetryBudgetMinRetries
+	}
+
+	// a window shorter than the buckets count would give a zero bucket width
+	return &RetryBudget{
+		config:      config,
+		bucketWidth: max(config.Window/retryBudgetBuckets, 1),
+	}
+}
+
+// request records a request that is sent for the first time.
+func (b *RetryBudget) request(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bucket(now).requests++
+}
+
+// withdraw records a retry and returns true if the budget allows it.
+func (b *RetryBudget) withdraw(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	requests, retries := 0, 0
+	for i := range b.buckets {
+		if now.Sub(b.buckets[i].start) < b.config.Window {
+			requests += b.buckets[i].requests
+			retries += b.buckets[i].retries
+		}
+	}
+
+	if float64(retries+1) > float64(requests)*b.config.Ratio && retries+1 > b.config.MinRetries {
+		return false
+	}
+	b.bucket(now).retries++
+
+	return true
+}
+
+// bucket returns the bucket of the time, it is reset if it belongs to a previous window.
+func (b *RetryBudget) bucket(now time.Time) *retryBudgetBucket {
+	start := now.Truncate(b.bucketWidth)
+	bucket := &b.buckets[(start.UnixNano()/int64(b.bucketWidth))%retryBudgetBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = retryBudgetBucket{start: start}
+	}
+
+	return bucket
+}
@@ -0,0 +1,103 @@
+package inpu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+)
+
+func (c *ClientSuite) Test_RetryBudget_Ratio() {
+	c.T().Parallel()
+	budget := NewRetryBudget(RetryBudgetConfig{Ratio: 0.1, Window: 10 * time.Second, MinRetries: -1})
+	now := time.Now()
+
+	for range 50 {
+		budget.request(now)
+	}
+	for range 5 {
+		c.Require().True(budget.withdraw(now))
+	}
+	c.Require().False(budget.withdraw(now))
+
+	// the requests and the retries leave the window
+	later := now.Add(11 * time.Second)
+	c.Require().False(budget.withdraw(later))
+	for range 10 {
+		budget.request(later)
+	}
+	c.Require().True(budget.withdraw(later))
+	c.Require().False(budget.withdraw(later))
+}
+
+func (c *ClientSuite) Test_RetryBudget_Min_Retries() {
+	c.T().Parallel()
+	budget := NewRetryBudget(RetryBudgetConfig{MinRetries: 2})
+	now := time.Now()
+
+	c.Require().True(budget.withdraw(now))
+	c.Require().True(budget.withdraw(now))
+	c.Require().False(budget.withdraw(now))
+}
+
+func (c *ClientSuite) Test_RetryBudget_Tiny_Window() {
+	c.T().Parallel()
+	budget := NewRetryBudget(RetryBudgetConfig{Window: time.Nanosecond, MinRetries: 1})
+	now := time.Now()
+
+	c.Require().NotPanics(func() {
+		budget.request(now)
+		c.Require().True(budget.withdraw(now))
+		c.Require().False(budget.withdraw(now))
+	})
+}
+
+func (c *ClientSuite) Test_RetryBudget_Limits_Retries_Of_Clients() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	budget := NewRetryBudget(RetryBudgetConfig{Ratio: 0.1, MinRetries: 3})
+	retry := RetryMiddlewareWithConfig(RetryConfig{
+		MaxRetries: 2,
+		Backoff:    ConstantBackoff(time.Millisecond),
+		Budget:     budget,
+	})
+	first := New().BasePath(server.URL).Use(retry)
+	second := New().BasePath(server.URL).Use(retry)
+
+	for range 5 {
+		c.Require().NoError(first.Get("/").Send())
+		c.Require().NoError(second.Get("/").Send())
+	}
+
+	// 10 requests and the 3 retries of MinRetries, 10% of 10 requests is less
+	c.Require().EqualValues(13, calls.Load())
+}
+
+func (c *ClientSuite) Test_RetryBudget_Exhausted_Returns_Attempts() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		c.Require().NoError(err)
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(RetryMiddlewareWithConfig(RetryConfig{
+		MaxRetries: 2,
+		Backoff:    ConstantBackoff(time.Millisecond),
+		Budget:     NewRetryBudget(RetryBudgetConfig{MinRetries: 1}),
+	}))
+
+	err := client.Get("/").Send()
+
+	var exhausted *RetryExhaustedError
+	c.Require().ErrorAs(err, &exhausted)
+	c.Require().ErrorIs(err, ErrConnectionFailed)
+	c.Require().Len(exhausted.Attempts, 2)
+}
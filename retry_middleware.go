@@ -2,8 +2,6 @@ package inpu
 
 import (
 	"context"
-	"crypto/tls"
-	"errors"
 	"net/http"
 	"slices"
 	"strconv"
@@ -19,21 +17,46 @@ var (
 		http.StatusLoopDetected, http.StatusVariantAlsoNegotiates,
 		http.StatusNetworkAuthenticationRequired,
 	}
+	// idempotentMethods can be sent many times with the same effect, RFC 9110 section 9.2.2
+	idempotentMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete,
+	}
+	// overloadStatuses are the retried statuses that tell the server is overloaded, they carry Retry-After
+	overloadStatuses = []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}
 )
 
 type CustomRetryChecker func(resp *http.Response, err error) bool
 
 type RetryConfig struct {
-	MaxRetries         int
-	InitialBackoff     time.Duration
-	MaxBackoff         time.Duration
-	BackoffMultiplier  float64
+	MaxRetries int
+	// InitialBackoff, MaxBackoff and BackoffMultiplier make the ExponentialBackoff used when Backoff is not set.
+	// A MaxBackoff above zero also caps the wait of a Retry-After header.
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	// Backoff decides the wait before every retry, like FullJitterBackoff. A Retry-After header is preferred.
+	Backoff Backoff
+	// Budget limits the retries to a ratio of the requests. It can be shared by many clients.
+	Budget *RetryBudget
+	// PerAttemptTimeout cancels an attempt that takes longer, so it can be retried. Zero means the attempts
+	// are only limited by the context of the request.
+	PerAttemptTimeout time.Duration
+	// RetryNonIdempotent retries every method. By default, POST and PATCH are only retried when they have an
+	// Idempotency-Key header, see IdempotencyKeyMiddleware.
+	RetryNonIdempotent bool
+	// ErrorClassifier decides which errors are retried, the transient and the unknown ones are. Default is
+	// ClassifyError.
+	ErrorClassifier    ErrorClassifier
 	CustomRetryChecker CustomRetryChecker
 }
 
 type retryMiddleware struct {
 	config RetryConfig
-	next   http.RoundTripper
+}
+
+type retryTransport struct {
+	*retryMiddleware
+	next http.RoundTripper
 }
 
 // RetryMiddleware creates a retry middleware with default config
@@ -48,6 +71,13 @@ func RetryMiddleware(maxRetries int) Middleware {
 
 // RetryMiddlewareWithConfig creates a retry middleware with custom config
 func RetryMiddlewareWithConfig(config RetryConfig) Middleware {
+	if config.Backoff == nil {
+		config.Backoff = ExponentialBackoff(config.InitialBackoff, config.MaxBackoff, config.BackoffMultiplier)
+	}
+	if config.ErrorClassifier == nil {
+		config.ErrorClassifier = ClassifyError
+	}
+
 	return &retryMiddleware{
 		config: config,
 	}
@@ -62,71 +92,144 @@ func (t *retryMiddleware) Priority() int {
 }
 
 func (t *retryMiddleware) Apply(next http.RoundTripper) http.RoundTripper {
-	t.next = next
-
-	return t
+	return &retryTransport{retryMiddleware: t, next: next}
 }
 
-func (t *retryMiddleware) RoundTrip(req *http.Request) (*http.Response, error) {
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	var resp *http.Response
 	var err error
 	logger := ExtractLoggerFromContext(req.Context())
 	ctx := req.Context()
+	clock := ExtractClockFromContext(ctx)
 
-	backoff := t.config.InitialBackoff
+	if t.config.Budget != nil {
+		t.config.Budget.request(clock.Now())
+	}
 
+	var backoff time.Duration
+	attempts := make([]RetryAttempt, 0, t.config.MaxRetries+1)
 	for attempt := 0; attempt <= t.config.MaxRetries; attempt++ {
 		// Clone request for retry (important for body)
 		clonedReq := t.cloneRequest(req)
-		clonedReq = clonedReq.WithContext(context.WithValue(clonedReq.Context(), ContextKeyRetryAttempt, attempt))
+		attemptCtx, cancel := t.attemptContext(context.WithValue(clonedReq.Context(), ContextKeyRetryAttempt, attempt))
+		clonedReq = clonedReq.WithContext(attemptCtx)
 		resp, err = t.next.RoundTrip(clonedReq)
+		attempts = append(attempts, newRetryAttempt(resp, err))
+		countAttempt(ctx)
 
 		// Check if we should retry
-		if !t.shouldRetry(resp, err, attempt) {
-			return resp, err
-		}
-		// Don't sleep after last attempt
-		if attempt < t.config.MaxRetries {
-			retryAfterDuration := t.extractBackoffFromHeader(resp)
-			timeToWait := favorRetryAfterValueIfNotEmpty(retryAfterDuration, backoff)
-			// Check context cancellation
-			select {
-			case <-req.Context().Done():
-				return resp, req.Context().Err()
-			case <-time.After(timeToWait):
-				logger.Debug(ctx, "[RETRY] Attempt %d/%d for %s %s (waiting %v)", attempt+1, t.config.MaxRetries,
-					req.Method, req.URL.Redacted(), timeToWait)
-				// drain the body and close the connection because
-				// it is going to send another request soon
-				// error is already logged
-				DrainBodyAndClose(resp)
+		if !t.shouldRetry(req, resp, err, attempt) {
+			if attempt > 0 && attempt == t.config.MaxRetries && t.isRetriable(req, resp, err) {
+				logger.Warn(ctx, "[RETRY] All %d retries exhausted for %s %s", t.config.MaxRetries,
+					req.Method, req.URL.Redacted())
+
+				return stopRetrying(resp, err, attempts, cancel)
 			}
 
-			// Exponential backoff
-			backoff = time.Duration(float64(backoff) * t.config.BackoffMultiplier)
-			backoff = t.getMaxBackoffTimeIfBigger(backoff)
+			return cancelWithBody(resp, cancel), err
 		}
-	}
 
-	logger.Warn(ctx, "[RETRY] All %d retries exhausted for %s %s", t.config.MaxRetries,
-		req.Method, req.URL.Redacted())
+		if t.config.Budget != nil && !t.config.Budget.withdraw(clock.Now()) {
+			logger.Warn(ctx, "[RETRY] Retry budget exhausted for %s %s", req.Method, req.URL.Redacted())
+
+			return stopRetrying(resp, err, attempts, cancel)
+		}
+
+		backoff = t.config.Backoff.Next(attempt+1, backoff)
+		retryAfterDuration := t.extractBackoffFromHeader(resp, clock.Now())
+		timeToWait := favorRetryAfterValueIfNotEmpty(retryAfterDuration, backoff)
+
+		// the next attempt could not finish before the deadline, waiting would only delay the failure.
+		// The deadline of a context is always in real time.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeToWait {
+			logger.Debug(ctx, "[RETRY] Not retrying %s %s, waiting %v would overrun the deadline", req.Method,
+				req.URL.Redacted(), timeToWait)
+
+			return stopRetrying(resp, err, attempts, cancel)
+		}
+
+		hooksFromContext(ctx).retry(RetryEvent{
+			Request:  req,
+			Response: resp,
+			Err:      err,
+			Attempt:  attempt + 1,
+			Wait:     timeToWait,
+		})
+		// Check context cancellation
+		select {
+		case <-req.Context().Done():
+			return cancelWithBody(resp, cancel), req.Context().Err()
+		case <-clock.After(timeToWait):
+			logger.Debug(ctx, "[RETRY] Attempt %d/%d for %s %s (waiting %v)", attempt+1, t.config.MaxRetries,
+				req.Method, req.URL.Redacted(), timeToWait)
+			// drain the body and close the connection because
+			// it is going to send another request soon
+			// error is already logged
+			DrainBodyAndClose(resp)
+			cancel()
+		}
+	}
 
 	return resp, err
 }
 
+// attemptContext returns the context of an attempt, it times out after PerAttemptTimeout.
+func (t *retryMiddleware) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.config.PerAttemptTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, t.config.PerAttemptTimeout)
+}
+
+// stopRetrying returns the result of the last attempt when a retriable request is not retried anymore, an error is
+// returned as a RetryExhaustedError with every attempt.
+func stopRetrying(resp *http.Response, err error, attempts []RetryAttempt, cancel context.CancelFunc,
+) (*http.Response, error) {
+	if err != nil {
+		cancel()
+
+		return nil, &RetryExhaustedError{Attempts: attempts}
+	}
+
+	return cancelWithBody(resp, cancel), nil
+}
+
+// cancelWithBody cancels the context of the attempt when the body of its response is closed,
+// the body could not be read after the context is cancelled.
+func cancelWithBody(resp *http.Response, cancel context.CancelFunc) *http.Response {
+	if resp == nil || resp.Body == nil {
+		cancel()
+
+		return resp
+	}
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp
+}
+
 func (t *retryMiddleware) getMaxBackoffTimeIfBigger(d time.Duration) time.Duration {
-	if d > t.config.MaxBackoff {
+	if t.config.MaxBackoff > 0 && d > t.config.MaxBackoff {
 		return t.config.MaxBackoff
 	}
 
 	return d
 }
 
-func (t *retryMiddleware) shouldRetry(resp *http.Response, err error, attempt int) bool {
+func (t *retryMiddleware) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) bool {
 	// No more retries left
 	if attempt >= t.config.MaxRetries {
 		return false
 	}
+
+	return t.isRetriable(req, resp, err)
+}
+
+func (t *retryMiddleware) isRetriable(req *http.Request, resp *http.Response, err error) bool {
+	// retrying a request that is not idempotent could apply it twice, like creating two orders
+	if !t.config.RetryNonIdempotent && !isIdempotent(req) {
+		return false
+	}
 	if t.config.CustomRetryChecker != nil {
 		if t.config.CustomRetryChecker(resp, err) {
 			return true
@@ -134,19 +237,16 @@ func (t *retryMiddleware) shouldRetry(resp *http.Response, err error, attempt in
 	}
 
 	if err != nil {
-		return checkRetryBasedOnConnectionError(err)
+		return t.config.ErrorClassifier(err) != ErrorClassPermanent
 	}
 
 	return checkRetryBasedOnStatusCode(resp)
 }
 
-func checkRetryBasedOnConnectionError(connectionError error) bool {
-	var certificateVerificationError *tls.CertificateVerificationError
-	if errors.As(connectionError, &certificateVerificationError) {
-		return false
-	}
-
-	return true
+// isIdempotent reports whether the request can be sent again safely: its method is idempotent,
+// or it has an Idempotency-Key header the server deduplicates it with.
+func isIdempotent(req *http.Request) bool {
+	return slices.Contains(idempotentMethods, req.Method) || req.Header.Get(HeaderIdempotencyKey) != ""
 }
 
 func checkRetryBasedOnStatusCode(response *http.Response) bool {
@@ -168,6 +268,10 @@ func checkRetryBasedOnStatusCode(response *http.Response) bool {
 	return false
 }
 
+func checkOverloadBasedOnStatusCode(response *http.Response) bool {
+	return response != nil && slices.Contains(overloadStatuses, response.StatusCode)
+}
+
 func (t *retryMiddleware) cloneRequest(req *http.Request) *http.Request {
 	clonedReq := req.Clone(req.Context())
 
@@ -188,10 +292,10 @@ func favorRetryAfterValueIfNotEmpty(retryAfter time.Duration, backoff time.Durat
 	return backoff
 }
 
-func (t *retryMiddleware) extractBackoffFromHeader(response *http.Response) time.Duration {
+func (t *retryMiddleware) extractBackoffFromHeader(response *http.Response, now time.Time) time.Duration {
 	if response != nil {
-		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable {
-			if sleep, ok := parseRetryAfterHeader(response.Header.Get(HeaderRetryAfter)); ok {
+		if checkOverloadBasedOnStatusCode(response) {
+			if sleep, ok := parseRetryAfterHeader(response.Header.Get(HeaderRetryAfter), now); ok {
 				return t.getMaxBackoffTimeIfBigger(sleep)
 			}
 		}
@@ -200,7 +304,7 @@ func (t *retryMiddleware) extractBackoffFromHeader(response *http.Response) time
 	return 0
 }
 
-func parseRetryAfterHeader(header string) (time.Duration, bool) {
+func parseRetryAfterHeader(header string, now time.Time) (time.Duration, bool) {
 	if len(header) == 0 {
 		return 0, false
 	}
@@ -217,7 +321,7 @@ func parseRetryAfterHeader(header string) (time.Duration, bool) {
 	if err != nil {
 		return 0, false
 	}
-	if until := retryTime.Sub(time.Now()); until > 0 {
+	if until := retryTime.Sub(now); until > 0 {
 		return until, true
 	}
 
@@ -1,14 +1,19 @@
 package inpu
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/denizgursoy/inpu/inputest"
 )
 
 func (c *ClientSuite) Test_RetryMiddleware() {
@@ -158,6 +163,47 @@ func (c *ClientSuite) Test_Wait_By_Retry_After_Value() {
 	c.Require().InDelta(duration, 2*time.Second, float64(time.Millisecond)*100)
 }
 
+func (c *ClientSuite) Test_Retry_After_Is_Not_Capped_With_Custom_Backoff() {
+	c.T().Parallel()
+	clock := inputest.NewFakeClock(time.Now())
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set(HeaderRetryAfter, "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	waits := make(chan time.Duration, 1)
+	client := New().
+		BasePath(server.URL).
+		Clock(clock).
+		Use(RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 1, Backoff: ConstantBackoff(time.Millisecond)})).
+		Hooks(Hooks{
+			OnRetry: func(event RetryEvent) {
+				waits <- event.Wait
+			},
+		})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Get("/").OnOk(ThenDoNothing).Send()
+	}()
+
+	c.Require().Equal(time.Second, <-waits)
+	clock.BlockUntil(1)
+	clock.Advance(time.Millisecond)
+	c.Require().EqualValues(1, calls.Load())
+	clock.Advance(time.Second)
+
+	c.Require().NoError(<-done)
+	c.Require().EqualValues(2, calls.Load())
+}
+
 func (c *ClientSuite) Test_Custom_Retry_Function() {
 	c.T().Parallel()
 	count := 0
@@ -188,3 +234,171 @@ func (c *ClientSuite) Test_Custom_Retry_Function() {
 	c.Require().NoError(err)
 	c.Require().Equal(3, count)
 }
+
+func (c *ClientSuite) Test_Retry_Only_Idempotent_Requests() {
+	c.T().Parallel()
+	var mu sync.Mutex
+	keys := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get(HeaderIdempotencyKey))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	retry := RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 2, Backoff: ConstantBackoff(time.Millisecond)})
+	client := New().BasePath(server.URL).Use(retry)
+	attempts := func(send func() error) int {
+		mu.Lock()
+		keys = keys[:0]
+		mu.Unlock()
+		c.Require().NoError(send())
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(keys)
+	}
+
+	c.Require().Equal(1, attempts(client.Post("/", nil).Send))
+	c.Require().Equal(1, attempts(client.Patch("/", nil).Send))
+	c.Require().Equal(3, attempts(client.Put("/", nil).Send))
+	c.Require().Equal(3, attempts(client.Delete("/", nil).Send))
+	c.Require().Equal(3, attempts(client.Post("/", nil).Header(HeaderIdempotencyKey, "order-1").Send))
+
+	unsafe := New().BasePath(server.URL).Use(RetryMiddlewareWithConfig(RetryConfig{
+		MaxRetries:         2,
+		Backoff:            ConstantBackoff(time.Millisecond),
+		RetryNonIdempotent: true,
+	}))
+	c.Require().Equal(3, attempts(unsafe.Post("/", nil).Send))
+
+	// the generated key is the same for every attempt
+	withKey := New().BasePath(server.URL).Use(retry, IdempotencyKeyMiddleware())
+	c.Require().Equal(3, attempts(withKey.Post("/", nil).Send))
+	c.Require().NotEmpty(keys[0])
+	c.Require().Equal([]string{keys[0], keys[0], keys[0]}, keys)
+
+	// every request has its own key
+	previous := keys[0]
+	c.Require().Equal(3, attempts(withKey.Post("/", nil).Send))
+	c.Require().NotEqual(previous, keys[0])
+}
+
+func (c *ClientSuite) Test_Retry_Per_Attempt_Timeout() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done()
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(RetryMiddlewareWithConfig(RetryConfig{
+		MaxRetries:        1,
+		Backoff:           ConstantBackoff(time.Millisecond),
+		PerAttemptTimeout: 50 * time.Millisecond,
+	}))
+
+	start := time.Now()
+	c.Require().NoError(client.Get("/").OnOk(ThenDoNothing).Send())
+	c.Require().Less(time.Since(start), time.Second)
+	c.Require().EqualValues(2, calls.Load())
+}
+
+func (c *ClientSuite) Test_Retry_Does_Not_Wait_Past_The_Deadline() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(RetryMiddlewareWithConfig(RetryConfig{
+		MaxRetries: 3,
+		Backoff:    ConstantBackoff(time.Second),
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	matched := false
+	start := time.Now()
+	c.Require().NoError(client.GetCtx(ctx, "/").
+		On(StatusIs(http.StatusServiceUnavailable), func(*http.Response) error {
+			matched = true
+
+			return nil
+		}).
+		Send())
+	c.Require().Less(time.Since(start), 150*time.Millisecond)
+	c.Require().True(matched)
+	c.Require().EqualValues(1, calls.Load())
+}
+
+func (c *ClientSuite) Test_Retry_Exhausted_Error() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+		// the other attempts fail without a response
+		conn, _, err := w.(http.Hijacker).Hijack()
+		c.Require().NoError(err)
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(RetryMiddlewareWithConfig(RetryConfig{
+		MaxRetries: 2,
+		Backoff:    ConstantBackoff(time.Millisecond),
+	}))
+
+	err := client.Get("/").Send()
+
+	var exhausted *RetryExhaustedError
+	c.Require().ErrorAs(err, &exhausted)
+	c.Require().ErrorIs(err, ErrConnectionFailed)
+	c.Require().Len(exhausted.Attempts, 3)
+	c.Require().Equal(http.StatusServiceUnavailable, exhausted.Attempts[0].StatusCode)
+	c.Require().NoError(exhausted.Attempts[0].Err)
+	c.Require().Error(exhausted.Attempts[1].Err)
+	c.Require().Error(exhausted.Attempts[2].Err)
+	c.Require().Contains(err.Error(), "all 3 attempts failed: attempt 1: status 503, attempt 2: ")
+}
+
+func (c *ClientSuite) Test_Retry_Exhausted_Error_When_Deadline_Is_Too_Close() {
+	c.T().Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		c.Require().NoError(err)
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	client := New().BasePath(server.URL).Use(RetryMiddlewareWithConfig(RetryConfig{
+		MaxRetries: 3,
+		Backoff:    ConstantBackoff(time.Hour),
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := client.GetCtx(ctx, "/").Send()
+
+	var exhausted *RetryExhaustedError
+	c.Require().ErrorAs(err, &exhausted)
+	c.Require().ErrorIs(err, ErrConnectionFailed)
+	c.Require().Len(exhausted.Attempts, 1)
+	c.Require().Error(exhausted.Attempts[0].Err)
+	c.Require().EqualValues(1, calls.Load())
+}
@@ -11,6 +11,14 @@ type StatusMatcher interface {
 	Priority() int
 }
 
+// StatusMatcherFunc creates a StatusMatcher from the provided function and priority.
+// The less priority is the higher precedence on the matching, see statusChecker.Priority for the built-in values.
+// Usage:
+// On(StatusMatcherFunc(func(statusCode int) bool { return statusCode/100 == 4 }, 5),func(r *http.Response) error{})
+func StatusMatcherFunc(matcher func(statusCode int) bool, priority int) StatusMatcher {
+	return newStatusChecker(matcher, priority)
+}
+
 func newStatusChecker(matcher func(statusCode int) bool, priority int) *statusChecker {
 	return &statusChecker{
 		matcher:  matcher,
@@ -32,7 +40,7 @@ func (s *statusChecker) Match(statusCode int) bool {
 // Current priorities are:
 // StatusIs -> 1
 // StatusIsOneOf -> 2
-// StatusIsInformational, StatusIsSuccess, StatusIsRedirection, StatusIsClientError, StatusIsServerError -> 3
+// StatusIsInformational, StatusIsSuccess, StatusIsRedirection, StatusIsClientError, StatusIsServerError, StatusBetween -> 3
 // StatusAnyExcept -> 8
 // StatusAnyExceptOneOf -> 9
 // StatusAny -> 10
@@ -108,6 +116,16 @@ var StatusIsServerError = newStatusChecker(func(statusCode int) bool {
 	return statusCode >= 500
 }, 3)
 
+// StatusBetween checks if the response status is between [lo,hi], both ends are inclusive.
+// It has the priority 3, same as the category matchers like StatusIsSuccess.
+// Usage:
+// On(StatusBetween(http.StatusBadRequest, http.StatusNotFound),func(r *http.Response) error{}) -> matches 400,401,402,403,404
+func StatusBetween(lo, hi int) StatusMatcher {
+	return newStatusChecker(func(statusCode int) bool {
+		return statusCode >= lo && statusCode <= hi
+	}, 3)
+}
+
 // StatusIsOneOf checks if the response status is one of the provided codes.
 // It has the priority 2, and it is checked after StatusIs.
 // Usage:
@@ -487,3 +487,24 @@ func (c *ClientSuite) Test_StatusNot() {
 	c.Require().False(Not(StatusIsUnauthorized).Match(http.StatusUnauthorized))
 	c.Require().True(Not(StatusIsClientError).Match(http.StatusBadGateway))
 }
+
+func (c *ClientSuite) Test_StatusBetween() {
+	c.T().Parallel()
+	matcher := StatusBetween(http.StatusBadRequest, http.StatusNotFound)
+	c.Require().True(matcher.Match(http.StatusBadRequest))
+	c.Require().True(matcher.Match(http.StatusForbidden))
+	c.Require().True(matcher.Match(http.StatusNotFound))
+	c.Require().False(matcher.Match(http.StatusMethodNotAllowed))
+	c.Require().False(matcher.Match(http.StatusPermanentRedirect))
+	c.Require().Equal(3, matcher.Priority())
+}
+
+func (c *ClientSuite) Test_StatusMatcherFunc() {
+	c.T().Parallel()
+	matcher := StatusMatcherFunc(func(statusCode int) bool {
+		return statusCode == http.StatusTeapot
+	}, 5)
+	c.Require().True(matcher.Match(http.StatusTeapot))
+	c.Require().False(matcher.Match(http.StatusOK))
+	c.Require().Equal(5, matcher.Priority())
+}
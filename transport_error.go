@@ -0,0 +1,171 @@
+package inpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+)
+
+const contextKeyAttempts = "inpu_attempts"
+
+var (
+	ErrDNSFailed        = errors.New("dns lookup failed")
+	ErrDialFailed       = errors.New("dial failed")
+	ErrTLSFailed        = errors.New("tls handshake failed")
+	ErrTimeout          = errors.New("request timed out")
+	ErrCanceled         = errors.New("request was canceled")
+	ErrProtocol         = errors.New("protocol error")
+	ErrTooManyRedirects = errors.New("too many redirects")
+	ErrBodyWrite        = errors.New("could not write the request")
+)
+
+// TransportErrorKind tells at which step a request failed without a response.
+type TransportErrorKind int
+
+const (
+	// TransportErrorOther is an error of another kind, like the error of a middleware.
+	TransportErrorOther TransportErrorKind = iota
+	// TransportErrorDNS means the host could not be resolved.
+	TransportErrorDNS
+	// TransportErrorDial means the connection to the server could not be opened, like a refused connection.
+	TransportErrorDial
+	// TransportErrorTLS means the TLS handshake failed, like an invalid certificate.
+	TransportErrorTLS
+	// TransportErrorTimeout means a timeout of the request, the client or the connection expired.
+	TransportErrorTimeout
+	// TransportErrorCanceled means the context of the request was cancelled.
+	TransportErrorCanceled
+	// TransportErrorProtocol means the server did not answer with valid HTTP, or closed or reset the connection before
+	// the response was read.
+	TransportErrorProtocol
+	// TransportErrorTooManyRedirects means the redirect policy of the client stopped following the redirects.
+	TransportErrorTooManyRedirects
+	// TransportErrorBodyWrite means the request could not be written to the connection.
+	TransportErrorBodyWrite
+)
+
+var transportErrorSentinels = map[TransportErrorKind]error{
+	TransportErrorDNS:              ErrDNSFailed,
+	TransportErrorDial:             ErrDialFailed,
+	TransportErrorTLS:              ErrTLSFailed,
+	TransportErrorTimeout:          ErrTimeout,
+	TransportErrorCanceled:         ErrCanceled,
+	TransportErrorProtocol:         ErrProtocol,
+	TransportErrorTooManyRedirects: ErrTooManyRedirects,
+	TransportErrorBodyWrite:        ErrBodyWrite,
+}
+
+func (k TransportErrorKind) String() string {
+	switch k {
+	case TransportErrorOther:
+		return "other"
+	case TransportErrorDNS:
+		return "dns"
+	case TransportErrorDial:
+		return "dial"
+	case TransportErrorTLS:
+		return "tls"
+	case TransportErrorTimeout:
+		return "timeout"
+	case TransportErrorCanceled:
+		return "canceled"
+	case TransportErrorProtocol:
+		return "protocol"
+	case TransportErrorTooManyRedirects:
+		return "too many redirects"
+	case TransportErrorBodyWrite:
+		return "body write"
+	default:
+		return fmt.Sprintf("TransportErrorKind(%d)", int(k))
+	}
+}
+
+// TransportError is returned when a request failed without a response. It is ErrConnectionFailed and the sentinel
+// of its kind for errors.Is, like ErrDNSFailed, and it unwraps to the error of the transport.
+// Usage:
+//
+//	err := client.Get("/users").Send()
+//	if errors.Is(err, ErrTimeout) {
+//		// retry later
+//	}
+//	var transportError *TransportError
+//	if errors.As(err, &transportError) {
+//		log.Printf("%s failed after %d attempts", transportError.Host, transportError.Attempts)
+//	}
+type TransportError struct {
+	Kind TransportErrorKind
+	Host string
+	// Attempts is the number of attempts sent by RetryMiddleware, 1 without it.
+	Attempts int
+	Err      error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%v (%s, host %s, %d attempts): %v", ErrConnectionFailed, e.Kind, e.Host, e.Attempts,
+		e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TransportError) Is(target error) bool {
+	return target == ErrConnectionFailed || target == transportErrorSentinels[e.Kind]
+}
+
+func newTransportError(ctx context.Context, host string, err error) *TransportError {
+	attempts := 1
+	if counter, ok := ctx.Value(contextKeyAttempts).(*atomic.Int32); ok {
+		attempts = max(attempts, int(counter.Load()))
+	}
+
+	return &TransportError{Kind: transportErrorKind(err), Host: host, Attempts: attempts, Err: err}
+}
+
+func transportErrorKind(err error) TransportErrorKind {
+	var dnsError *net.DNSError
+	var opError *net.OpError
+	var netError net.Error
+	message := err.Error()
+
+	switch {
+	case errors.Is(err, ErrTooManyRedirects):
+		return TransportErrorTooManyRedirects
+	case errors.Is(err, context.Canceled):
+		return TransportErrorCanceled
+	case errors.As(err, &dnsError):
+		return TransportErrorDNS
+	case isTLSError(err) || strings.Contains(message, "tls: "):
+		return TransportErrorTLS
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netError) && netError.Timeout()):
+		return TransportErrorTimeout
+	case errors.As(err, &opError) && opError.Op == "dial":
+		return TransportErrorDial
+	case errors.As(err, &opError) && opError.Op == "write":
+		return TransportErrorBodyWrite
+	case strings.Contains(message, "malformed HTTP") || strings.Contains(message, "http2: ") ||
+		strings.Contains(message, "unsupported protocol scheme"):
+		return TransportErrorProtocol
+	// the request was written, the connection was closed or reset while the response was read
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		(errors.As(err, &opError) && opError.Op == "read"):
+		return TransportErrorProtocol
+	default:
+		return TransportErrorOther
+	}
+}
+
+// contextWithAttemptCounter adds the counter RetryMiddleware counts its attempts with, for TransportError.Attempts.
+func contextWithAttemptCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyAttempts, new(atomic.Int32))
+}
+
+func countAttempt(ctx context.Context) {
+	if counter, ok := ctx.Value(contextKeyAttempts).(*atomic.Int32); ok {
+		counter.Add(1)
+	}
+}
@@ -0,0 +1,146 @@
+package inpu
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+)
+
+func (c *ClientSuite) requireTransportError(err error, kind TransportErrorKind, sentinel error) *TransportError {
+	var transportError *TransportError
+	c.Require().ErrorAs(err, &transportError)
+	c.Require().Equal(kind, transportError.Kind)
+	c.Require().ErrorIs(err, sentinel)
+	c.Require().ErrorIs(err, ErrConnectionFailed)
+
+	return transportError
+}
+
+func (c *ClientSuite) Test_TransportError_Dial_With_Attempts() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := New().Use(RetryMiddlewareWithConfig(RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond}))
+	err := client.Get(server.URL).Send()
+
+	transportError := c.requireTransportError(err, TransportErrorDial, ErrDialFailed)
+	c.Require().Equal(server.Listener.Addr().String(), transportError.Host)
+	c.Require().Equal(3, transportError.Attempts)
+	c.Require().ErrorIs(err, syscall.ECONNREFUSED)
+}
+
+func (c *ClientSuite) Test_TransportError_DNS() {
+	c.T().Parallel()
+	client := New().Use(RequestModifierMiddleware(func(req *http.Request) (*http.Request, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: req.URL.Hostname(), IsNotFound: true}
+	}, "dns-middleware", 0))
+
+	err := client.Get("http://example.invalid").Send()
+
+	transportError := c.requireTransportError(err, TransportErrorDNS, ErrDNSFailed)
+	c.Require().Equal("example.invalid", transportError.Host)
+	c.Require().Equal(1, transportError.Attempts)
+	c.Require().NotErrorIs(err, ErrTimeout)
+}
+
+func (c *ClientSuite) Test_TransportError_TLS() {
+	c.T().Parallel()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	c.requireTransportError(New().Get(server.URL).Send(), TransportErrorTLS, ErrTLSFailed)
+}
+
+func (c *ClientSuite) Test_TransportError_Timeout_And_Canceled() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	err := New().Get(server.URL).TimeOutIn(10 * time.Millisecond).Send()
+	c.requireTransportError(err, TransportErrorTimeout, ErrTimeout)
+	c.Require().ErrorIs(err, context.DeadlineExceeded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	err = New().GetCtx(ctx, server.URL).Send()
+	c.requireTransportError(err, TransportErrorCanceled, ErrCanceled)
+	c.Require().ErrorIs(err, context.Canceled)
+}
+
+func (c *ClientSuite) Test_TransportError_Too_Many_Redirects() {
+	c.T().Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	err := New().FollowRedirects(2).Get(server.URL).Send()
+	c.requireTransportError(err, TransportErrorTooManyRedirects, ErrTooManyRedirects)
+
+	// the default redirect policy of http.Client
+	err = New().Get(server.URL).Send()
+	c.requireTransportError(err, TransportErrorTooManyRedirects, ErrTooManyRedirects)
+}
+
+func (c *ClientSuite) Test_TransportError_Protocol() {
+	c.T().Parallel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Require().NoError(err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// the garbage is written after the request is read, otherwise the client may not have sent it yet
+		if _, err := http.ReadRequest(bufio.NewReader(conn)); err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte("not http\r\n\r\n"))
+	}()
+
+	err = New().Get("http://" + listener.Addr().String()).Send()
+	c.requireTransportError(err, TransportErrorProtocol, ErrProtocol)
+}
+
+func (c *ClientSuite) Test_TransportError_Connection_Closed_Before_Response() {
+	c.T().Parallel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Require().NoError(err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = http.ReadRequest(bufio.NewReader(conn))
+		_ = conn.Close()
+	}()
+
+	err = New().Get("http://" + listener.Addr().String()).Send()
+	c.requireTransportError(err, TransportErrorProtocol, ErrProtocol)
+}
+
+func (c *ClientSuite) Test_TransportError_Kind() {
+	c.T().Parallel()
+	c.Require().Equal(TransportErrorBodyWrite,
+		transportErrorKind(&net.OpError{Op: "write", Err: syscall.EPIPE}))
+	c.Require().Equal(TransportErrorProtocol, transportErrorKind(&url.Error{Op: "Get", URL: "/", Err: io.EOF}))
+	c.Require().Equal(TransportErrorProtocol,
+		transportErrorKind(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}))
+	c.Require().Equal(TransportErrorOther, transportErrorKind(errors.New("stopped after 10 redirects")))
+	c.Require().Equal(TransportErrorOther, transportErrorKind(ErrCircuitOpen))
+	c.Require().Equal(TransportErrorOther, transportErrorKind(errors.New("custom")))
+}